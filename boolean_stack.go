@@ -26,6 +26,7 @@ func addBooleanFunctions(ds *datastack) {
 
 	ds.FunctionMap["="] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(2) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -34,6 +35,7 @@ func addBooleanFunctions(ds *datastack) {
 
 	ds.FunctionMap["and"] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(2) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -44,6 +46,7 @@ func addBooleanFunctions(ds *datastack) {
 
 	ds.FunctionMap["or"] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(2) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -54,6 +57,7 @@ func addBooleanFunctions(ds *datastack) {
 
 	ds.FunctionMap["xor"] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(2) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -64,6 +68,7 @@ func addBooleanFunctions(ds *datastack) {
 
 	ds.FunctionMap["not"] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(1) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -72,6 +77,7 @@ func addBooleanFunctions(ds *datastack) {
 
 	ds.FunctionMap["frominteger"] = func(d DataStack, r RunSet, i Interpreter) {
 		if r.Bad("integer", 1) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -80,6 +86,7 @@ func addBooleanFunctions(ds *datastack) {
 
 	ds.FunctionMap["fromfloat"] = func(d DataStack, r RunSet, i Interpreter) {
 		if r.Bad("float", 1) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -0,0 +1,150 @@
+package spogoto
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// charNames are the names of characters that can not be written after a
+// backslash in a char literal.
+var charNames = map[string]rune{
+	"space":   ' ',
+	"newline": '\n',
+	"tab":     '\t',
+	"return":  '\r',
+}
+
+// NewCharStack generates a char DataStack.
+func NewCharStack(chars []rune) *datastack {
+	elements := Elements{}
+	for _, v := range chars {
+		elements = append(elements, v)
+	}
+	d := NewDataStack(elements, FunctionMap{}, func(str string) (Element, bool) {
+		c, ok := parseChar(str)
+		return Element(c), ok
+	})
+	addCharFunctions(d)
+	return d
+}
+
+// CharStackConstructor creates an empty char stack.
+func CharStackConstructor() (string, DataStack) {
+	return "char", NewCharStack([]rune{})
+}
+
+// parseChar converts a char literal like \a, \space or \newline to a rune.
+func parseChar(str string) (rune, bool) {
+	if len(str) < 2 || str[0] != '\\' {
+		return 0, false
+	}
+
+	name := str[1:]
+	if c, ok := charNames[name]; ok {
+		return c, true
+	}
+
+	c, size := utf8.DecodeRuneInString(name)
+	if c == utf8.RuneError || size != len(name) {
+		return 0, false
+	}
+	return c, true
+}
+
+// formatChar formats c as a char literal.
+func formatChar(c rune) string {
+	for name, named := range charNames {
+		if c == named {
+			return "\\" + name
+		}
+	}
+	return "\\" + string(c)
+}
+
+// charFromInteger converts n to an ASCII character.
+func charFromInteger(n int64) rune {
+	n %= 128
+	if n < 0 {
+		n += 128
+	}
+	return rune(n)
+}
+
+func addCharFunctions(ds *datastack) {
+
+	ds.FunctionMap["isletter"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		r.Stack("boolean").Push(unicode.IsLetter(d.Pop().(rune)))
+	}
+
+	ds.FunctionMap["isdigit"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		r.Stack("boolean").Push(unicode.IsDigit(d.Pop().(rune)))
+	}
+
+	ds.FunctionMap["iswhitespace"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		r.Stack("boolean").Push(unicode.IsSpace(d.Pop().(rune)))
+	}
+
+	ds.FunctionMap["="] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(2) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		r.Stack("boolean").Push(d.Pop().(rune) == d.Pop().(rune))
+	}
+
+	ds.FunctionMap["frominteger"] = func(d DataStack, r RunSet, i Interpreter) {
+		if r.Bad("integer", 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		d.Push(charFromInteger(r.Stack("integer").Pop().(int64)))
+	}
+
+	ds.FunctionMap["fromfloat"] = func(d DataStack, r RunSet, i Interpreter) {
+		if r.Bad("float", 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		d.Push(charFromInteger(int64(r.Stack("float").Pop().(float64))))
+	}
+
+	ds.FunctionMap["tostring"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		r.Stack("string").Push(string(d.Pop().(rune)))
+	}
+
+	ds.FunctionMap["allfromstring"] = func(d DataStack, r RunSet, i Interpreter) {
+		if r.Bad("string", 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		runes := []rune(r.Stack("string").Pop().(string))
+		for k := len(runes) - 1; k >= 0; k-- {
+			d.Push(runes[k])
+		}
+	}
+
+}
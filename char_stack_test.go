@@ -0,0 +1,82 @@
+package spogoto
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func charElements(chars []rune) Elements {
+	elements := Elements{}
+	for _, v := range chars {
+		elements = append(elements, v)
+	}
+	return elements
+}
+
+func TestCharStackFunctions(t *testing.T) {
+	testData := []struct {
+		code         string
+		charsAfter   []rune
+		boolsAfter   []bool
+		intsAfter    []int64
+		stringsAfter []string
+	}{
+		{`\a char.isletter`, []rune{}, []bool{true}, []int64{}, []string{}},
+		{`\5 char.isletter`, []rune{}, []bool{false}, []int64{}, []string{}},
+		{`\5 char.isdigit`, []rune{}, []bool{true}, []int64{}, []string{}},
+		{`\space char.iswhitespace`, []rune{}, []bool{true}, []int64{}, []string{}},
+		{`\a \a char.=`, []rune{}, []bool{true}, []int64{}, []string{}},
+		{`65 char.frominteger`, []rune{'A'}, []bool{}, []int64{}, []string{}},
+		{`-63 char.frominteger`, []rune{'A'}, []bool{}, []int64{}, []string{}},
+		{`65.7 char.fromfloat`, []rune{'A'}, []bool{}, []int64{}, []string{}},
+		{`\A integer.fromchar`, []rune{}, []bool{}, []int64{65}, []string{}},
+		{`\newline char.tostring`, []rune{}, []bool{}, []int64{}, []string{"\n"}},
+		{`"abc" char.allfromstring`, []rune{'c', 'b', 'a'}, []bool{}, []int64{}, []string{}},
+		{`(\( \))`, []rune{'(', ')'}, []bool{}, []int64{}, []string{}},
+		{`char.isletter char.tostring char.allfromstring`, []rune{}, []bool{}, []int64{}, []string{}},
+	}
+
+	for _, d := range testData {
+		Convey(fmt.Sprintf("Running code `%s`", d.code), t, func() {
+			i := NewInterpreter(DefaultOptions)
+			r := i.Run(CodeFromString(d.code), StackState{})
+
+			Convey(fmt.Sprintf("Char stack should be %v", d.charsAfter), func() {
+				So(r.Stack("char").Elements(), ShouldResemble, charElements(d.charsAfter))
+				So(r.Stack("boolean").Elements(), ShouldResemble, boolElements(d.boolsAfter))
+				So(r.Stack("integer").Elements(), ShouldResemble, int64Elements(d.intsAfter))
+				So(r.Stack("string").Elements(), ShouldResemble, stringElements(d.stringsAfter))
+			})
+		})
+	}
+}
+
+func TestCharLiterals(t *testing.T) {
+	Convey("Given char literals", t, func() {
+		data := []struct {
+			literal string
+			char    rune
+		}{
+			{`\a`, 'a'},
+			{`\space`, ' '},
+			{`\newline`, '\n'},
+			{`\tab`, '\t'},
+			{`\é`, 'é'},
+		}
+
+		for _, d := range data {
+			c, ok := parseChar(d.literal)
+			So(ok, ShouldBeTrue)
+			So(c, ShouldEqual, d.char)
+			So(formatChar(c), ShouldEqual, d.literal)
+		}
+	})
+
+	Convey("Given invalid char literals", t, func() {
+		for _, literal := range []string{`\`, `\ab`, `a`} {
+			_, ok := parseChar(literal)
+			So(ok, ShouldBeFalse)
+		}
+	})
+}
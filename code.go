@@ -1,17 +1,99 @@
 package spogoto
 
 import (
-	"regexp"
-	"strings"
+	"bytes"
+	"unicode"
 )
 
+// Code is a list of string items. Parentheses are separate items that
+// enclose nested blocks of code.
 type Code []string
 
+// String joins the items of the Code back into a string that keeps the
+// nesting of blocks.
 func (c Code) String() string {
-	return strings.Join([]string(c), " ")
+	var buf bytes.Buffer
+	for k, item := range c {
+		if k > 0 && item != ")" && c[k-1] != "(" {
+			buf.WriteString(" ")
+		}
+		buf.WriteString(item)
+	}
+	return buf.String()
 }
 
+// CodeFromString splits str into Code items. Items are separated by
+// whitespace and parentheses are items of their own. Double quoted string
+// literals and bracketed vector literals are single items even if they
+// contain whitespace. A backslash starting an item escapes the character
+// after it.
 func CodeFromString(str string) Code {
-	re := regexp.MustCompile("\\s+")
-	return Code(re.Split(str, -1))
+	code := Code{}
+	var item bytes.Buffer
+	flush := func() {
+		if item.Len() > 0 {
+			code = append(code, item.String())
+			item.Reset()
+		}
+	}
+
+	runes := []rune(str)
+	for k := 0; k < len(runes); k++ {
+		c := runes[k]
+		switch {
+		case c == '"' && item.Len() == 0:
+			k = scanString(runes, k, &item)
+			flush()
+		case c == '[' && item.Len() == 0:
+			k = scanVector(runes, k, &item)
+			flush()
+		case c == '\\' && item.Len() == 0:
+			item.WriteRune(c)
+			if k+1 < len(runes) {
+				k++
+				item.WriteRune(runes[k])
+			}
+		case unicode.IsSpace(c):
+			flush()
+		case c == '(' || c == ')':
+			flush()
+			code = append(code, string(c))
+		default:
+			item.WriteRune(c)
+		}
+	}
+	flush()
+
+	return code
+}
+
+// scanString writes the string literal starting at runes[start] to item
+// and returns the index of its closing quote.
+func scanString(runes []rune, start int, item *bytes.Buffer) int {
+	item.WriteRune(runes[start])
+	for k := start + 1; k < len(runes); k++ {
+		item.WriteRune(runes[k])
+		switch runes[k] {
+		case '\\':
+			if k+1 < len(runes) {
+				k++
+				item.WriteRune(runes[k])
+			}
+		case '"':
+			return k
+		}
+	}
+	return len(runes)
+}
+
+// scanVector writes the vector literal starting at runes[start] to item
+// and returns the index of its closing bracket.
+func scanVector(runes []rune, start int, item *bytes.Buffer) int {
+	for k := start; k < len(runes); k++ {
+		item.WriteRune(runes[k])
+		if runes[k] == ']' {
+			return k
+		}
+	}
+	return len(runes)
 }
@@ -0,0 +1,274 @@
+package spogoto
+
+import (
+	"strconv"
+)
+
+// NewCodeStack generates a code DataStack. Elements of the code stack are
+// either a single Instruction or a Code list.
+func NewCodeStack(elements Elements) *datastack {
+	d := NewDataStack(elements, FunctionMap{}, func(str string) (Element, bool) {
+		return nil, false
+	})
+	addCodeFunctions(d)
+	return d
+}
+
+// CodeStackConstructor creates an empty code stack.
+func CodeStackConstructor() (string, DataStack) {
+	return "code", NewCodeStack(Elements{})
+}
+
+// toCode converts a code stack element to a new Code. An Instruction is
+// treated as a list with a single item.
+func toCode(e Element) Code {
+	switch v := e.(type) {
+	case Instruction:
+		return v.Code()
+	case Code:
+		return append(Code{}, v...)
+	}
+	return Code{}
+}
+
+// itemCode converts a code stack element to Code that can be an item of
+// a list. Lists are enclosed in parentheses.
+func itemCode(e Element) Code {
+	if list, ok := e.(Code); ok {
+		return append(append(Code{"("}, list...), ")")
+	}
+	return toCode(e)
+}
+
+// codeItems returns the Instructions that make up a code stack element.
+func codeItems(e Element, i Interpreter) InstructionSet {
+	if in, ok := e.(Instruction); ok {
+		return InstructionSet{in}
+	}
+	return i.Parse(toCode(e))
+}
+
+// codePoints counts the points of Code. Each item and each list, including
+// the Code itself, is a point.
+func codePoints(c Code) int64 {
+	points := int64(1)
+	for _, item := range c {
+		if item != ")" {
+			points++
+		}
+	}
+	return points
+}
+
+// combineCode replaces the top two code elements with the Code built by
+// combine. It is a noop if the Code would go over Options.MaxPoints.
+func combineCode(d DataStack, r RunSet, i Interpreter, combine func(first, second Element) Code) {
+	second := d.Pop()
+	first := d.Pop()
+	c := combine(first, second)
+	if !withinLimit(codePoints(c), i.Config().MaxPoints, defaultMaxPoints) {
+		d.Push(first)
+		d.Push(second)
+		r.Noop(SizeLimitExceeded)
+		return
+	}
+
+	d.Push(c)
+}
+
+// fromInstruction converts an Instruction to a code stack element. Blocks
+// become Code lists.
+func fromInstruction(in Instruction) Element {
+	if in.IsBlock() {
+		return in.Block.Code()
+	}
+	return in
+}
+
+// codeElement converts a literal to a code stack element using the
+// Interpreter's Parser.
+func codeElement(literal string, i Interpreter) (Element, bool) {
+	parsed := i.Parse(Code{literal})
+	if len(parsed) != 1 {
+		return nil, false
+	}
+	return parsed[0], true
+}
+
+func addCodeFunctions(ds *datastack) {
+
+	ds.FunctionMap["quote"] = func(d DataStack, r RunSet, i Interpreter) {
+		args, ok := execArgs(r, 1)
+		if !ok {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		d.Push(fromInstruction(args[0]))
+	}
+
+	ds.FunctionMap["do"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		pushExec(r.Stack("exec"), codeItems(d.Pop(), i)...)
+	}
+
+	ds.FunctionMap["cons"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(2) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		combineCode(d, r, i, func(item, list Element) Code {
+			return append(itemCode(item), toCode(list)...)
+		})
+	}
+
+	ds.FunctionMap["list"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(2) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		combineCode(d, r, i, func(first, second Element) Code {
+			return append(itemCode(first), itemCode(second)...)
+		})
+	}
+
+	ds.FunctionMap["append"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(2) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		combineCode(d, r, i, func(first, second Element) Code {
+			return append(toCode(first), toCode(second)...)
+		})
+	}
+
+	ds.FunctionMap["car"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		items := codeItems(d.Pop(), i)
+		if len(items) == 0 {
+			d.Push(Code{})
+		} else {
+			d.Push(fromInstruction(items[0]))
+		}
+	}
+
+	ds.FunctionMap["cdr"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		items := codeItems(d.Pop(), i)
+		if len(items) == 0 {
+			d.Push(Code{})
+		} else {
+			d.Push(items[1:].Code())
+		}
+	}
+
+	ds.FunctionMap["length"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		r.Stack("integer").Push(int64(len(codeItems(d.Pop(), i))))
+	}
+
+	ds.FunctionMap["nth"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) || r.Bad("integer", 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		n := r.Stack("integer").Pop().(int64)
+		items := codeItems(d.Pop(), i)
+		if len(items) == 0 {
+			d.Push(Code{})
+			return
+		}
+
+		d.Push(fromInstruction(items[wrapIndex(n, len(items))]))
+	}
+
+	ds.FunctionMap["atom"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		_, ok := d.Pop().(Instruction)
+		r.Stack("boolean").Push(ok)
+	}
+
+	ds.FunctionMap["null"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		r.Stack("boolean").Push(len(codeItems(d.Pop(), i)) == 0)
+	}
+
+	ds.FunctionMap["="] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(2) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		c1 := itemCode(d.Pop())
+		c2 := itemCode(d.Pop())
+		r.Stack("boolean").Push(c1.String() == c2.String())
+	}
+
+	ds.FunctionMap["frominteger"] = func(d DataStack, r RunSet, i Interpreter) {
+		if r.Bad("integer", 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		literal := strconv.FormatInt(r.Stack("integer").Pop().(int64), 10)
+		if e, ok := codeElement(literal, i); ok {
+			d.Push(e)
+		}
+	}
+
+	ds.FunctionMap["fromfloat"] = func(d DataStack, r RunSet, i Interpreter) {
+		if r.Bad("float", 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		literal, ok := formatFloat(r.Stack("float").Pop().(float64))
+		if !ok {
+			return
+		}
+		if e, ok := codeElement(literal, i); ok {
+			d.Push(e)
+		}
+	}
+
+	ds.FunctionMap["fromboolean"] = func(d DataStack, r RunSet, i Interpreter) {
+		if r.Bad("boolean", 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		literal := strconv.FormatBool(r.Stack("boolean").Pop().(bool))
+		if e, ok := codeElement(literal, i); ok {
+			d.Push(e)
+		}
+	}
+
+}
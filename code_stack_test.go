@@ -0,0 +1,94 @@
+package spogoto
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestCodeFunctions(t *testing.T) {
+	testData := []struct {
+		code      string
+		codeAfter Elements
+		intsAfter []int64
+	}{
+		{"code.quote integer.+", Elements{NewInstruction("integer", "integer.+", "+")}, []int64{}},
+		{"code.quote 1 code.quote 2 code.cons", Elements{Code{"1", "2"}}, []int64{}},
+		{"code.quote 1 code.quote 2 code.append", Elements{Code{"1", "2"}}, []int64{}},
+		{"code.quote 1 code.quote 2 code.cons code.car", Elements{NewInstruction("integer", "1", "")}, []int64{}},
+		{"code.quote 1 code.quote 2 code.cons code.cdr", Elements{Code{"2"}}, []int64{}},
+		{"code.quote 1 code.cdr", Elements{Code{}}, []int64{}},
+		{"code.quote 1 code.quote 2 code.cons code.length", Elements{}, []int64{2}},
+		{"code.quote 1 code.quote 2 code.cons 3 code.nth", Elements{NewInstruction("integer", "2", "")}, []int64{}},
+		{"code.quote (1 2 3) -1 code.nth", Elements{NewInstruction("integer", "3", "")}, []int64{}},
+		{"code.quote (1 2 3) -9223372036854775808 code.nth", Elements{NewInstruction("integer", "2", "")}, []int64{}},
+		{"code.quote 1 code.quote 2 code.cons code.do", Elements{}, []int64{1, 2}},
+		{"5 code.frominteger", Elements{NewInstruction("integer", "5", "")}, []int64{}},
+		{"2.0 code.fromfloat", Elements{NewInstruction("float", "2.0", "")}, []int64{}},
+		{"5 code.frominteger code.do", Elements{}, []int64{5}},
+		{"code.do code.car code.nth", Elements{}, []int64{}},
+		{"code.quote 3 code.quote (1 2) code.cons", Elements{Code{"3", "1", "2"}}, []int64{}},
+		{"code.quote (1 2) code.quote 3 code.cons", Elements{Code{"(", "1", "2", ")", "3"}}, []int64{}},
+		{"code.quote 3 code.quote (1 2) code.list", Elements{Code{"3", "(", "1", "2", ")"}}, []int64{}},
+		{"code.quote (1 2) code.quote 3 code.append", Elements{Code{"1", "2", "3"}}, []int64{}},
+		{"code.quote ((1 2) 3) code.car", Elements{Code{"1", "2"}}, []int64{}},
+		{"code.quote ((1 2) 3) code.cdr", Elements{Code{"3"}}, []int64{}},
+		{"code.quote ((1 2) 3) code.length", Elements{}, []int64{2}},
+		{"code.quote ((1 2) 3) code.do", Elements{}, []int64{1, 2, 3}},
+	}
+
+	for _, d := range testData {
+		Convey(fmt.Sprintf("Running code `%s`", d.code), t, func() {
+			i := NewInterpreter(DefaultOptions)
+			r := i.Run(CodeFromString(d.code), StackState{})
+
+			Convey(fmt.Sprintf("Code stack should be %v", d.codeAfter), func() {
+				So(r.Stack("code").Elements(), ShouldResemble, d.codeAfter)
+				So(r.Stack("integer").Elements(), ShouldResemble, int64Elements(d.intsAfter))
+			})
+		})
+	}
+
+	Convey("Given code that keeps growing", t, func() {
+		i := NewInterpreter(DefaultOptions)
+		r := i.Run(CodeFromString("code.quote (1 2) exec.y (code.dup code.append)"), StackState{})
+
+		Convey("append stops at the max points", func() {
+			So(codePoints(r.Stack("code").Peek().(Code)), ShouldBeLessThanOrEqualTo, defaultMaxPoints)
+			So(r.Errors(), ShouldContain, RunError{-1, "code.append", SizeLimitExceeded})
+		})
+	})
+
+	Convey("Given a max points option", t, func() {
+		options := DefaultOptions
+		options.MaxPoints = 3
+
+		for _, code := range []string{
+			"code.quote (1 2) code.quote 3 code.append",
+			"code.quote 1 code.quote (2 3) code.cons",
+			"code.quote 1 code.quote (2 3) code.list",
+		} {
+			r := NewInterpreter(options).Run(CodeFromString(code), StackState{})
+
+			Convey(fmt.Sprintf("`%s` leaves the code stack unchanged", code), func() {
+				So(r.Stack("code").Size(), ShouldEqual, 2)
+				So(r.Errors()[0].Kind, ShouldEqual, SizeLimitExceeded)
+			})
+		}
+
+		Convey("a negative max means there is no limit", func() {
+			options.MaxPoints = -1
+			r := NewInterpreter(options).Run(CodeFromString("code.quote (1 2) code.quote 3 code.append"), StackState{})
+			So(r.Stack("code").Elements(), ShouldResemble, Elements{Code{"1", "2", "3"}})
+		})
+	})
+
+	Convey("Given code elements", t, func() {
+		i := NewInterpreter(DefaultOptions)
+		r := i.Run(CodeFromString("code.quote 1 code.atom code.quote 1 code.cdr code.null"), StackState{})
+
+		Convey("atom and null describe them", func() {
+			So(r.Stack("boolean").Elements(), ShouldResemble, boolElements([]bool{true, true}))
+		})
+	})
+}
@@ -22,4 +22,35 @@ func TestCode(t *testing.T) {
 			"true 4 cursor.gotoif 2 1 8",
 		)
 	})
+
+	Convey("Given a string of code with parentheses", t, func() {
+		str := "(1 (2)) 3)"
+		code := CodeFromString(str)
+
+		Convey("Parentheses are separate items", func() {
+			So(code, ShouldResemble, Code{"(", "1", "(", "2", ")", ")", "3", ")"})
+		})
+
+		Convey("String() keeps the nesting", func() {
+			So(code.String(), ShouldEqual, str)
+			So(CodeFromString(code.String()), ShouldResemble, code)
+		})
+	})
+
+	Convey("Given an empty string", t, func() {
+		So(CodeFromString("  "), ShouldResemble, Code{})
+	})
+
+	Convey("Given a string of code with string literals", t, func() {
+		str := `"a (b) c" "say \"hi\"" ("x")`
+		code := CodeFromString(str)
+
+		Convey("String literals are single items", func() {
+			So(code, ShouldResemble, Code{`"a (b) c"`, `"say \"hi\""`, "(", `"x"`, ")"})
+		})
+
+		Convey("String() keeps the literals", func() {
+			So(code.String(), ShouldEqual, str)
+		})
+	})
 }
@@ -1,5 +1,9 @@
 package spogoto
 
+import (
+	"strconv"
+)
+
 // FunctionMap is a map of functions that operate on the DataStack and
 // other DataStacks accessible through the RunSet.
 type FunctionMap map[string]func(DataStack, RunSet, Interpreter)
@@ -12,6 +16,7 @@ type DataStack interface {
 	Functions() FunctionMap
 	Call(string, RunSet, Interpreter)
 	PushLiteral(string)
+	Clone() DataStack
 }
 
 type DataStackConstructor func() (string, DataStack)
@@ -37,19 +42,35 @@ func NewDataStack(elements Elements, functions FunctionMap, fn ConversionFunc) *
 func addCommonFunctions(ds *datastack) {
 
 	ds.FunctionMap["pop"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
 		d.Pop()
 	}
 
 	ds.FunctionMap["swap"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(2) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
 		d.Swap()
 	}
 
 	ds.FunctionMap["rotate"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(3) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
 		d.Rotate()
 	}
 
 	ds.FunctionMap["shove"] = func(d DataStack, r RunSet, i Interpreter) {
 		if r.Bad("integer", 1) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -59,6 +80,7 @@ func addCommonFunctions(ds *datastack) {
 
 	ds.FunctionMap["yank"] = func(d DataStack, r RunSet, i Interpreter) {
 		if r.Bad("integer", 1) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -68,6 +90,7 @@ func addCommonFunctions(ds *datastack) {
 
 	ds.FunctionMap["yankdup"] = func(d DataStack, r RunSet, i Interpreter) {
 		if r.Bad("integer", 1) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -84,8 +107,88 @@ func addCommonFunctions(ds *datastack) {
 	}
 
 	ds.FunctionMap["dup"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
 		d.Dup()
 	}
+
+	ds.FunctionMap["define"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) || r.Bad("name", 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		in, ok := literalInstruction(d.Peek(), i)
+		if !ok {
+			return
+		}
+
+		d.Pop()
+		r.Bind(r.Stack("name").Pop().(string), in)
+	}
+}
+
+// ElementType returns the type of the DataStack that holds elements like e.
+// It returns false if no DataStack holds such elements.
+func ElementType(e Element) (string, bool) {
+	switch e.(type) {
+	case int64:
+		return "integer", true
+	case float64:
+		return "float", true
+	case bool:
+		return "boolean", true
+	case string:
+		return "string", true
+	case rune:
+		return "char", true
+	case []int64:
+		return "vector_integer", true
+	case []float64:
+		return "vector_float", true
+	case []bool:
+		return "vector_boolean", true
+	case Code, Instruction:
+		return "code", true
+	}
+	return "", false
+}
+
+// literalInstruction converts an element to an Instruction that pushes
+// the element back to its stack when executed.
+func literalInstruction(e Element, i Interpreter) (Instruction, bool) {
+	var code Code
+	switch v := e.(type) {
+	case Instruction:
+		return v, true
+	case Code:
+		code = itemCode(v)
+	case int64:
+		code = Code{strconv.FormatInt(v, 10)}
+	case float64:
+		str, ok := formatFloat(v)
+		if !ok {
+			return Instruction{}, false
+		}
+		code = Code{str}
+	case bool:
+		code = Code{strconv.FormatBool(v)}
+	case string:
+		code = Code{strconv.Quote(v)}
+	case rune:
+		code = Code{formatChar(v)}
+	case []int64, []float64, []bool:
+		code = Code{formatVector(v)}
+	}
+
+	parsed := i.Parse(code)
+	if len(parsed) != 1 {
+		return Instruction{}, false
+	}
+	return parsed[0], true
 }
 
 // Functions returns the FunctionMap of the datastack
@@ -110,6 +213,12 @@ func (s *datastack) PushLiteral(sval string) {
 	}
 }
 
+// Clone returns a copy of the datastack with its own elements. The
+// FunctionMap and ConversionFunc are shared since they don't change.
+func (s *datastack) Clone() DataStack {
+	return &datastack{s.stack.clone(), s.FunctionMap, s.ConversionFunc}
+}
+
 // A NullDataStack is a DataStack that has nothing and does nothing.
 type NullDataStack struct {
 	datastack
@@ -128,3 +237,8 @@ func (s *NullDataStack) Call(method string, r RunSet, i Interpreter) {
 // PushLiteral accepts a string literal but does practically nothing.
 func (s *NullDataStack) PushLiteral(sval string) {
 }
+
+// Clone returns a new NullDataStack.
+func (s *NullDataStack) Clone() DataStack {
+	return &NullDataStack{}
+}
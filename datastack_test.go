@@ -127,6 +127,18 @@ func TestDataStack(t *testing.T) {
 			})
 
 		})
+
+		Convey("When Clone()d", func() {
+			c := s.Clone()
+			c.Pop()
+			c.Push("x")
+
+			Convey("The clone has its own elements and the same functions", func() {
+				So(s.Elements(), ShouldResemble, Elements{"a", "b", "c", "d"})
+				So(c.Elements(), ShouldResemble, Elements{"a", "b", "c", "x"})
+				So(c.Functions(), ShouldContainKey, "pop")
+			})
+		})
 	})
 
 	Convey("Given an empty datastack", t, func() {
@@ -152,3 +164,27 @@ func TestDataStack(t *testing.T) {
 	})
 
 }
+
+func TestElementType(t *testing.T) {
+	Convey("Elements have the type of the DataStack that holds them", t, func() {
+		for _, d := range []struct {
+			element   Element
+			stackType string
+		}{
+			{int64(1), "integer"},
+			{1.5, "float"},
+			{true, "boolean"},
+			{"a", "string"},
+			{'a', "char"},
+			{[]int64{1}, "vector_integer"},
+			{Code{"1"}, "code"},
+		} {
+			stackType, ok := ElementType(d.element)
+			So(ok, ShouldBeTrue)
+			So(stackType, ShouldEqual, d.stackType)
+		}
+
+		_, ok := ElementType(struct{}{})
+		So(ok, ShouldBeFalse)
+	})
+}
@@ -0,0 +1,78 @@
+package spogoto
+
+import (
+	"sync"
+)
+
+// Task is Code to be run on a StackState with Inputs.
+type Task struct {
+	Code   Code
+	State  StackState
+	Inputs Inputs
+}
+
+// Evaluator runs work concurrently on a pool of workers. Each piece of
+// work gets a copy of the Interpreter with its own Rand that is seeded from
+// Seed and the index of the work. Results don't depend on the number of
+// workers or on the order in which the work is done.
+type Evaluator struct {
+	Interpreter Interpreter
+	Workers     int
+	Seed        int64
+}
+
+// NewEvaluator constructs a new Evaluator with a number of workers.
+func NewEvaluator(i Interpreter, workers int, seed int64) *Evaluator {
+	return &Evaluator{Interpreter: i, Workers: workers, Seed: seed}
+}
+
+// Map calls fn for each index from 0 to n - 1 on the workers and waits
+// until all calls are done. fn must only write to data owned by its
+// index. No more workers than calls are started. With a single worker the
+// calls run on the calling goroutine.
+func (e *Evaluator) Map(n int, fn func(k int, i Interpreter)) {
+	workers := e.Workers
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		// Not worth starting goroutines for
+		for k := 0; k < n; k++ {
+			fn(k, e.Interpreter.WithRand(taskRand(e.Seed, k)))
+		}
+		return
+	}
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range indices {
+				fn(k, e.Interpreter.WithRand(taskRand(e.Seed, k)))
+			}
+		}()
+	}
+
+	for k := 0; k < n; k++ {
+		indices <- k
+	}
+	close(indices)
+	wg.Wait()
+}
+
+// Run runs the tasks and returns their RunSets in the same order.
+func (e *Evaluator) Run(tasks []Task) []RunSet {
+	results := make([]RunSet, len(tasks))
+	e.Map(len(tasks), func(k int, i Interpreter) {
+		results[k] = i.RunWithInputs(tasks[k].Code, tasks[k].State, tasks[k].Inputs)
+	})
+	return results
+}
+
+// taskRand creates the Rand of the kth task. It is split from the kth
+// value of a SplitMix64 seeded with seed.
+func taskRand(seed int64, k int) Rand {
+	return &SplitMix64{mix64(uint64(seed) + uint64(k+1)*golden)}
+}
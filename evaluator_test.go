@@ -0,0 +1,73 @@
+package spogoto
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestEvaluator(t *testing.T) {
+	Convey("Given tasks that use random values", t, func() {
+		i := NewInterpreter(DefaultOptions)
+		tasks := []Task{}
+		for k := 0; k < 20; k++ {
+			tasks = append(tasks, Task{
+				Code:  CodeFromString("integer.rand float.rand integer.+"),
+				State: StackState{"integer": Elements{int64(k * 100)}},
+			})
+		}
+
+		tops := func(results []RunSet) []Elements {
+			values := []Elements{}
+			for _, r := range results {
+				values = append(values, Elements{r.Stack("integer").Peek(), r.Stack("float").Peek()})
+			}
+			return values
+		}
+
+		Convey("When run on many workers", func() {
+			results := NewEvaluator(i, 4, 42).Run(tasks)
+
+			Convey("The results are in the order of the tasks", func() {
+				So(results, ShouldHaveLength, 20)
+				for k, r := range results {
+					top, _ := r.TopInteger()
+					So(top, ShouldBeBetweenOrEqual, k*100, k*100+9)
+				}
+			})
+
+			Convey("The results don't depend on the number of workers", func() {
+				So(tops(NewEvaluator(i, 1, 42).Run(tasks)), ShouldResemble, tops(results))
+				So(tops(NewEvaluator(i, 7, 42).Run(tasks)), ShouldResemble, tops(results))
+			})
+
+			Convey("A reused Evaluator gives the same results", func() {
+				e := NewEvaluator(i, 4, 42)
+				e.Run(tasks[:1])
+				So(tops(e.Run(tasks)), ShouldResemble, tops(results))
+				So(tops(e.Run(tasks[:1])), ShouldResemble, tops(results[:1]))
+			})
+
+			Convey("Other seeds give other results", func() {
+				So(tops(NewEvaluator(i, 4, 43).Run(tasks)), ShouldNotResemble, tops(results))
+			})
+		})
+
+		Convey("Map calls the function for each index with its own Rand", func() {
+			draw := func() []float64 {
+				values := make([]float64, 20)
+				NewEvaluator(i, 3, 42).Map(20, func(k int, c Interpreter) {
+					values[k] = c.RandFloat()
+				})
+				return values
+			}
+
+			values := draw()
+			distinct := map[float64]bool{}
+			for _, v := range values {
+				distinct[v] = true
+			}
+			So(distinct, ShouldHaveLength, 20)
+			So(draw(), ShouldResemble, values)
+		})
+	})
+}
@@ -0,0 +1,270 @@
+package spogoto
+
+import (
+	"strconv"
+)
+
+// NewExecStack generates an exec DataStack. The exec stack holds the
+// Instructions that are pending execution. The interpreter executes the
+// Instructions on the exec stack before moving the Cursor forward.
+func NewExecStack(instructions InstructionSet) *datastack {
+	elements := Elements{}
+	for _, v := range instructions {
+		elements = append(elements, v)
+	}
+	d := NewDataStack(elements, FunctionMap{}, func(str string) (Element, bool) {
+		return nil, false
+	})
+	addExecFunctions(d)
+	return d
+}
+
+// ExecStackConstructor creates an empty exec stack.
+func ExecStackConstructor() (string, DataStack) {
+	return "exec", NewExecStack(InstructionSet{})
+}
+
+// execArgs takes the next n Instructions to be executed. They are taken
+// from the exec stack first and then from the instructions following the
+// Cursor. The first Instruction returned is the one that would have been
+// executed first.
+func execArgs(r RunSet, n int64) (InstructionSet, bool) {
+	exec := r.Stack("exec")
+	cursor := r.Cursor()
+	fromExec := exec.Size()
+	if fromExec > n {
+		fromExec = n
+	}
+	fromCursor := n - fromExec
+	// The Cursor can be past the end when all arguments are on the exec
+	// stack, such as after a cursor.skipif inside a block
+	if fromCursor > 0 && cursor.Position+fromCursor >= int64(len(cursor.Instructions)) {
+		return nil, false
+	}
+
+	args := InstructionSet{}
+	var k int64
+	for k = 0; k < fromExec; k++ {
+		args = append(args, exec.Pop().(Instruction))
+	}
+	for k = 0; k < fromCursor; k++ {
+		cursor.Position++
+		args = append(args, cursor.Instructions[cursor.Position])
+	}
+
+	return args, true
+}
+
+// execSize returns the number of Instructions pending execution on the
+// exec stack and after the Cursor.
+func execSize(r RunSet) int64 {
+	cursor := r.Cursor()
+	pending := int64(len(cursor.Instructions)) - cursor.Position - 1
+	if pending < 0 {
+		pending = 0
+	}
+	return r.Stack("exec").Size() + pending
+}
+
+// pushExec pushes instructions to the exec stack so that the first
+// instruction is on top and will be executed first.
+func pushExec(d DataStack, instructions ...Instruction) {
+	for k := len(instructions) - 1; k >= 0; k-- {
+		d.Push(instructions[k])
+	}
+}
+
+func addExecFunctions(ds *datastack) {
+
+	// The common stack functions are replaced so that they also operate on
+	// the Instructions after the Cursor.
+	ds.FunctionMap["pop"] = func(d DataStack, r RunSet, i Interpreter) {
+		if _, ok := execArgs(r, 1); !ok {
+			r.Noop(StackUnderflow)
+		}
+	}
+
+	ds.FunctionMap["swap"] = func(d DataStack, r RunSet, i Interpreter) {
+		args, ok := execArgs(r, 2)
+		if !ok {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		pushExec(d, args[1], args[0])
+	}
+
+	ds.FunctionMap["rotate"] = func(d DataStack, r RunSet, i Interpreter) {
+		args, ok := execArgs(r, 3)
+		if !ok {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		pushExec(d, args[2], args[0], args[1])
+	}
+
+	ds.FunctionMap["flush"] = func(d DataStack, r RunSet, i Interpreter) {
+		d.Flush()
+		if cursor := r.Cursor(); cursor.Position < int64(len(cursor.Instructions)) {
+			cursor.Position = int64(len(cursor.Instructions)) - 1
+		}
+	}
+
+	ds.FunctionMap["stackdepth"] = func(d DataStack, r RunSet, i Interpreter) {
+		r.Stack("integer").Push(execSize(r))
+	}
+
+	ds.FunctionMap["yank"] = func(d DataStack, r RunSet, i Interpreter) {
+		if r.Bad("integer", 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		idx := r.Stack("integer").Pop().(int64)
+		if idx < 0 || idx >= execSize(r) {
+			return
+		}
+
+		args, ok := execArgs(r, idx+1)
+		if !ok {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		pushExec(d, append(InstructionSet{args[idx]}, args[:idx]...)...)
+	}
+
+	ds.FunctionMap["yankdup"] = func(d DataStack, r RunSet, i Interpreter) {
+		if r.Bad("integer", 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		idx := r.Stack("integer").Pop().(int64)
+		if idx < 0 || idx >= execSize(r) {
+			return
+		}
+
+		args, ok := execArgs(r, idx+1)
+		if !ok {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		pushExec(d, append(InstructionSet{args[idx]}, args...)...)
+	}
+
+	ds.FunctionMap["shove"] = func(d DataStack, r RunSet, i Interpreter) {
+		if r.Bad("integer", 1) || execSize(r) < 1 {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		idx := r.Stack("integer").Pop().(int64)
+		if idx < 0 || idx >= execSize(r) {
+			return
+		}
+
+		// The next Instruction goes after the idx Instructions following it
+		args, ok := execArgs(r, idx+1)
+		if !ok {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		pushExec(d, append(append(InstructionSet{}, args[1:]...), args[0])...)
+	}
+
+	ds.FunctionMap["dup"] = func(d DataStack, r RunSet, i Interpreter) {
+		args, ok := execArgs(r, 1)
+		if !ok {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		pushExec(d, args[0], args[0])
+	}
+
+	ds.FunctionMap["if"] = func(d DataStack, r RunSet, i Interpreter) {
+		if r.Bad("boolean", 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		args, ok := execArgs(r, 2)
+		if !ok {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		if r.Stack("boolean").Pop().(bool) {
+			pushExec(d, args[0])
+		} else {
+			pushExec(d, args[1])
+		}
+	}
+
+	ds.FunctionMap["do*times"] = func(d DataStack, r RunSet, i Interpreter) {
+		if r.Bad("integer", 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		args, ok := execArgs(r, 1)
+		if !ok {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		times := r.Stack("integer").Pop().(int64)
+		if times < 1 {
+			return
+		}
+
+		body := args[0]
+		if times == 1 {
+			pushExec(d, body)
+			return
+		}
+
+		pushExec(
+			d, body,
+			NewInstruction("integer", strconv.FormatInt(times-1, 10), ""),
+			NewInstruction("exec", "exec.do*times", "do*times"),
+			body,
+		)
+	}
+
+	ds.FunctionMap["y"] = func(d DataStack, r RunSet, i Interpreter) {
+		args, ok := execArgs(r, 1)
+		if !ok {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		pushExec(d, args[0], NewInstruction("exec", "exec.y", "y"), args[0])
+	}
+
+	ds.FunctionMap["k"] = func(d DataStack, r RunSet, i Interpreter) {
+		args, ok := execArgs(r, 2)
+		if !ok {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		pushExec(d, args[0])
+	}
+
+	ds.FunctionMap["s"] = func(d DataStack, r RunSet, i Interpreter) {
+		args, ok := execArgs(r, 3)
+		if !ok {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		a, b, c := args[0], args[1], args[2]
+		pushExec(d, a, c, b, c)
+	}
+
+}
@@ -0,0 +1,283 @@
+package spogoto
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestExecFunctions(t *testing.T) {
+	testData := spogotoCodeTestData{
+		{
+			"Dup",
+			"exec.dup 1 2",
+			"Should execute the next instruction twice",
+			[]int64{}, []int64{1, 1, 2},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Dup with nothing to execute",
+			"1 exec.dup",
+			"Will do nothing",
+			[]int64{}, []int64{1},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"If with true",
+			"true exec.if 1 2 3",
+			"Should execute the first instruction only",
+			[]int64{}, []int64{1, 3},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"If with false",
+			"false exec.if 1 2 3",
+			"Should execute the second instruction only",
+			[]int64{}, []int64{2, 3},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"If with empty boolean stack",
+			"exec.if 1 2 3",
+			"Will do nothing",
+			[]int64{}, []int64{1, 2, 3},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Do*times",
+			"1 3 exec.do*times integer.dup 4",
+			"Should execute the next instruction a number of times",
+			[]int64{}, []int64{1, 1, 1, 1, 4},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Do*times with zero",
+			"1 0 exec.do*times integer.dup 4",
+			"Should skip the next instruction",
+			[]int64{}, []int64{1, 4},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Nested exec instructions",
+			"exec.dup exec.k 1 2 3",
+			"Should take arguments from the exec stack before the cursor",
+			[]int64{}, []int64{2},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"If with blocks",
+			"false exec.if (1 2) (3 4) 5",
+			"Should execute the second block only",
+			[]int64{}, []int64{3, 4, 5},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Do*times with a block",
+			"2 exec.do*times (1 2)",
+			"Should execute the whole block a number of times",
+			[]int64{}, []int64{1, 2, 1, 2},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"K",
+			"exec.k 1 2 3",
+			"Should discard the second instruction",
+			[]int64{}, []int64{1, 3},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"S",
+			"exec.s 1 2 3 4",
+			"Should execute the instructions as a, c, b, c",
+			[]int64{}, []int64{1, 3, 2, 3, 4},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Pop",
+			"exec.pop 1 2",
+			"Should skip the next instruction",
+			[]int64{}, []int64{2},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Pop with nothing to execute",
+			"1 exec.pop",
+			"Will do nothing",
+			[]int64{}, []int64{1},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Swap",
+			"exec.swap 1 2 3",
+			"Should execute the next two instructions in reverse",
+			[]int64{}, []int64{2, 1, 3},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Rotate",
+			"exec.rotate 1 2 3 4",
+			"Should execute the third instruction first",
+			[]int64{}, []int64{3, 1, 2, 4},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Flush",
+			"1 exec.flush 2 3",
+			"Should skip the rest of the instructions",
+			[]int64{}, []int64{1},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Flush from the exec stack",
+			"exec.dup (exec.flush 1) 2",
+			"Should skip all pending instructions",
+			[]int64{}, []int64{},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Stackdepth",
+			"exec.stackdepth 1 (2 3)",
+			"Should count the pending instructions",
+			[]int64{}, []int64{2, 1, 2, 3},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Yank",
+			"2 exec.yank 10 20 30 40",
+			"Should execute the instruction at the index first",
+			[]int64{}, []int64{30, 10, 20, 40},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Yank with an index out of range",
+			"5 exec.yank 10 20",
+			"Will do nothing",
+			[]int64{}, []int64{10, 20},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Yankdup",
+			"1 exec.yankdup 10 20 30",
+			"Should execute a copy of the instruction at the index first",
+			[]int64{}, []int64{20, 10, 20, 30},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Shove",
+			"2 exec.shove 10 20 30 40",
+			"Should move the next instruction to the index",
+			[]int64{}, []int64{20, 30, 10, 40},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Shove to the bottom",
+			"1 exec.shove 10 20",
+			"Should move the next instruction after all the others",
+			[]int64{}, []int64{20, 10},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Yank after the cursor moved past the end",
+			"(true cursor.skipif 0 exec.yank 1)",
+			"Should take the instructions from the exec stack",
+			[]int64{}, []int64{1},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Yankdup after the cursor moved past the end",
+			"(true cursor.skipif 0 exec.yankdup 1)",
+			"Should take the instructions from the exec stack",
+			[]int64{}, []int64{1, 1},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Shove after the cursor moved past the end",
+			"(true cursor.skipif 0 exec.shove 1 2)",
+			"Should take the instructions from the exec stack",
+			[]int64{}, []int64{1, 2},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Shove with an index out of range",
+			"2 exec.shove 10 20",
+			"Will do nothing",
+			[]int64{}, []int64{10, 20},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Y",
+			"1 exec.y integer.dup",
+			"Should keep executing the next instruction until the limit",
+			[]int64{}, []int64{1, 1, 1, 1, 1, 1, 1},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+	}
+	for _, d := range testData {
+		Convey(fmt.Sprintf("%s on code `%s`", d.toTest, d.code), t, func() {
+			i := NewInterpreter(Options{
+				MaxInstructions:   12,
+				StackConstructors: DefaultOptions.StackConstructors,
+			})
+			var r RunSet
+			s := StackState{}
+
+			Convey(d.expectation, func() {
+				So(func() { r = i.Run(CodeFromString(d.code), s) }, ShouldNotPanic)
+				So(r.Stack("integer").Elements(), ShouldResemble, int64Elements(d.intsAfter))
+				So(r.Stack("boolean").Elements(), ShouldResemble, boolElements(d.boolsAfter))
+				So(r.Stack("float").Elements(), ShouldResemble, float64Elements(d.floatsAfter))
+			})
+		})
+	}
+
+	Convey("Given code that halts with pending exec instructions", t, func() {
+		i := NewInterpreter(DefaultOptions)
+		r := i.Run(CodeFromString("exec.dup cursor.end"), StackState{})
+
+		Convey("The exec stack is emptied", func() {
+			So(r.HaltReason(), ShouldEqual, HaltEnd)
+			So(r.Stack("exec").Size(), ShouldEqual, 0)
+		})
+	})
+
+	Convey("Given exec errors", t, func() {
+		i := NewInterpreter(DefaultOptions)
+		r := i.Run(CodeFromString("exec.dup exec.if"), StackState{})
+
+		Convey("Errors from exec instructions have index -1", func() {
+			So(r.Errors(), ShouldResemble, []RunError{
+				{-1, "exec.if", StackUnderflow},
+				{-1, "exec.if", StackUnderflow},
+			})
+		})
+	})
+}
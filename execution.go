@@ -0,0 +1,123 @@
+package spogoto
+
+// Hook is called before and after each Instruction is executed. Hooks can
+// be used to build tracers and debuggers. After is called for every
+// executed Instruction, including the last one of a run. When the run
+// halts after an Instruction, such as when it is the last one or it
+// reaches the instruction limit, the RunSet is already halted when After
+// is called.
+type Hook interface {
+	Before(Instruction, RunSet)
+	After(Instruction, RunSet)
+}
+
+// HookFuncs is a Hook made of functions. Functions that are nil are not
+// called.
+type HookFuncs struct {
+	BeforeFunc func(Instruction, RunSet)
+	AfterFunc  func(Instruction, RunSet)
+}
+
+// Before calls BeforeFunc.
+func (h HookFuncs) Before(in Instruction, r RunSet) {
+	if h.BeforeFunc != nil {
+		h.BeforeFunc(in, r)
+	}
+}
+
+// After calls AfterFunc.
+func (h HookFuncs) After(in Instruction, r RunSet) {
+	if h.AfterFunc != nil {
+		h.AfterFunc(in, r)
+	}
+}
+
+// Execution is a run of code that is executed step by step. Execution can
+// be paused at breakpoints on Cursor positions or on symbols.
+type Execution struct {
+	interpreter *interpreter
+	runset      *runset
+	hooks       []Hook
+	positions   map[int64]bool
+	symbols     map[string]bool
+}
+
+func newExecution(i *interpreter, r *runset) *Execution {
+	return &Execution{
+		interpreter: i,
+		runset:      r,
+		hooks:       append([]Hook{}, i.Options.Hooks...),
+		positions:   map[int64]bool{},
+		symbols:     map[string]bool{},
+	}
+}
+
+// RunSet returns the RunSet of the Execution.
+func (e *Execution) RunSet() RunSet {
+	return e.runset
+}
+
+// AddHook adds a Hook that is called only for this Execution.
+func (e *Execution) AddHook(h Hook) {
+	e.hooks = append(e.hooks, h)
+}
+
+// Position returns the position of the Cursor.
+func (e *Execution) Position() int64 {
+	return e.runset.Cursor().Position
+}
+
+// Next returns the Instruction that will be executed next. It returns
+// false if there is none.
+func (e *Execution) Next() (Instruction, bool) {
+	in, _, ok := e.interpreter.next(e.runset)
+	return in, ok
+}
+
+// Halted returns true if the execution has stopped. It is true as soon as
+// there is nothing left to execute.
+func (e *Execution) Halted() bool {
+	return e.runset.HaltReason() != NotHalted
+}
+
+// Step executes the next Instruction. It returns false if the execution
+// has halted and nothing was executed.
+func (e *Execution) Step() bool {
+	return e.interpreter.step(e.runset, e.hooks)
+}
+
+// Continue executes Instructions until the next one is at a breakpoint or
+// the execution halts. The next Instruction is always executed even if it
+// is at a breakpoint so that repeated calls move forward. It returns true
+// if it stopped at a breakpoint.
+func (e *Execution) Continue() bool {
+	if !e.Step() {
+		return false
+	}
+	for {
+		in, idx, ok := e.interpreter.next(e.runset)
+		if ok && (e.symbols[in.Value] || (idx >= 0 && e.positions[idx])) {
+			return true
+		}
+		if !e.Step() {
+			return false
+		}
+	}
+}
+
+// BreakAt sets a breakpoint on the Instruction at a Cursor position.
+func (e *Execution) BreakAt(position int64) {
+	e.positions[position] = true
+}
+
+// BreakOn sets a breakpoint on every Instruction with a symbol, such as
+// "integer.+".
+func (e *Execution) BreakOn(symbol string) {
+	e.symbols[symbol] = true
+}
+
+// ClearBreakpoints removes all breakpoints.
+func (e *Execution) ClearBreakpoints() {
+	e.positions = map[int64]bool{}
+	e.symbols = map[string]bool{}
+}
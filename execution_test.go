@@ -0,0 +1,166 @@
+package spogoto
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestExecution(t *testing.T) {
+	Convey("Given an Execution of some code", t, func() {
+		i := NewInterpreter(DefaultOptions)
+		code := CodeFromString("1 2 integer.+ 3 integer.* 4 integer.+")
+		e := i.Start(code, StackState{})
+
+		Convey("Nothing is executed before stepping", func() {
+			So(e.Position(), ShouldEqual, 0)
+			So(e.RunSet().InstructionCount(), ShouldEqual, 0)
+			So(e.Halted(), ShouldBeFalse)
+			next, ok := e.Next()
+			So(ok, ShouldBeTrue)
+			So(next.Value, ShouldEqual, "1")
+		})
+
+		Convey("Step executes one Instruction", func() {
+			So(e.Step(), ShouldBeTrue)
+			So(e.Step(), ShouldBeTrue)
+			So(e.Position(), ShouldEqual, 2)
+			So(e.RunSet().Stack("integer").Elements(), ShouldResemble, Elements{int64(1), int64(2)})
+			next, _ := e.Next()
+			So(next.Value, ShouldEqual, "integer.+")
+		})
+
+		Convey("The execution halts as soon as the last Instruction is executed", func() {
+			for k := 0; k < 6; k++ {
+				e.Step()
+			}
+			So(e.Halted(), ShouldBeFalse)
+			So(e.Step(), ShouldBeTrue)
+			So(e.Halted(), ShouldBeTrue)
+			So(e.RunSet().HaltReason(), ShouldEqual, HaltCompleted)
+			_, ok := e.Next()
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("Stepping to the end halts like Run", func() {
+			steps := 0
+			for e.Step() {
+				steps++
+			}
+			complete := i.Run(code, StackState{})
+			So(steps, ShouldEqual, 7)
+			So(e.Halted(), ShouldBeTrue)
+			So(e.Step(), ShouldBeFalse)
+			So(e.RunSet().HaltReason(), ShouldEqual, HaltCompleted)
+			So(e.RunSet().InstructionCount(), ShouldEqual, complete.InstructionCount())
+			So(e.RunSet().Stack("integer").Elements(), ShouldResemble, complete.Stack("integer").Elements())
+		})
+
+		Convey("Continue without breakpoints runs to the end", func() {
+			So(e.Continue(), ShouldBeFalse)
+			So(e.RunSet().Stack("integer").Peek(), ShouldEqual, int64(13))
+		})
+
+		Convey("Continue stops before an Instruction at a breakpoint", func() {
+			e.BreakAt(4)
+			So(e.Continue(), ShouldBeTrue)
+			So(e.Position(), ShouldEqual, 4)
+			So(e.RunSet().Stack("integer").Elements(), ShouldResemble, Elements{int64(3), int64(3)})
+
+			Convey("And moves past it when called again", func() {
+				So(e.Continue(), ShouldBeFalse)
+				So(e.RunSet().Stack("integer").Peek(), ShouldEqual, int64(13))
+			})
+		})
+
+		Convey("Continue stops before each Instruction with a symbol", func() {
+			e.BreakOn("integer.+")
+			So(e.Continue(), ShouldBeTrue)
+			So(e.Position(), ShouldEqual, 2)
+			So(e.Continue(), ShouldBeTrue)
+			So(e.Position(), ShouldEqual, 6)
+			So(e.RunSet().Stack("integer").Elements(), ShouldResemble, Elements{int64(9), int64(4)})
+			So(e.Continue(), ShouldBeFalse)
+		})
+
+		Convey("Cleared breakpoints are ignored", func() {
+			e.BreakAt(2)
+			e.BreakOn("integer.*")
+			e.ClearBreakpoints()
+			So(e.Continue(), ShouldBeFalse)
+		})
+
+		Convey("Hooks are called before and after each Instruction", func() {
+			log := []string{}
+			e.AddHook(HookFuncs{
+				BeforeFunc: func(in Instruction, r RunSet) {
+					log = append(log, "before "+in.Value)
+				},
+				AfterFunc: func(in Instruction, r RunSet) {
+					log = append(log, "after "+in.Value)
+				},
+			})
+			e.Step()
+			e.Step()
+			So(log, ShouldResemble, []string{"before 1", "after 1", "before 2", "after 2"})
+		})
+
+		Convey("After hooks see the run halted by the last Instruction", func() {
+			reasons := []HaltReason{}
+			e.AddHook(HookFuncs{
+				AfterFunc: func(in Instruction, r RunSet) {
+					reasons = append(reasons, r.HaltReason())
+				},
+			})
+			e.Continue()
+			So(reasons, ShouldHaveLength, 7)
+			So(reasons[5], ShouldEqual, NotHalted)
+			So(reasons[6], ShouldEqual, HaltCompleted)
+		})
+	})
+
+	Convey("Given an Execution of empty code", t, func() {
+		i := NewInterpreter(DefaultOptions)
+		e := i.Start(Code{}, StackState{})
+
+		Convey("It is halted before stepping", func() {
+			So(e.Halted(), ShouldBeTrue)
+			So(e.RunSet().HaltReason(), ShouldEqual, HaltCompleted)
+			So(e.Step(), ShouldBeFalse)
+			So(e.RunSet().InstructionCount(), ShouldEqual, 0)
+		})
+	})
+
+	Convey("Given Hooks in the Options", t, func() {
+		depths := []int64{}
+		options := DefaultOptions
+		options.Hooks = []Hook{HookFuncs{
+			AfterFunc: func(in Instruction, r RunSet) {
+				depths = append(depths, r.Stack("integer").Size())
+			},
+		}}
+		i := NewInterpreter(options)
+
+		Convey("They are called during Run", func() {
+			i.Run(CodeFromString("1 2 integer.+"), StackState{})
+			So(depths, ShouldResemble, []int64{1, 2, 1})
+		})
+
+		Convey("They are called for the Instruction that reaches the limit", func() {
+			limited := options
+			limited.MaxInstructions = 2
+			reasons := []HaltReason{}
+			limited.Hooks = []Hook{HookFuncs{
+				AfterFunc: func(in Instruction, r RunSet) {
+					reasons = append(reasons, r.HaltReason())
+				},
+			}}
+			NewInterpreter(limited).Run(CodeFromString("1 2 3 4"), StackState{})
+			So(reasons, ShouldResemble, []HaltReason{NotHalted, NotHalted, HaltMaxInstructions})
+		})
+
+		Convey("They are called for Instructions from the exec stack", func() {
+			i.Run(CodeFromString("1"), StackState{"exec": Elements{i.Parse(Code{"2"})[0]}})
+			So(depths, ShouldResemble, []int64{1, 2})
+		})
+	})
+}
@@ -3,6 +3,7 @@ package spogoto
 import (
 	"math"
 	"strconv"
+	"strings"
 )
 
 // NewFloatStack generates a float DataStack.
@@ -23,10 +24,25 @@ func FloatStackConstructor() (string, DataStack) {
 	return "float", NewFloatStack([]float64{})
 }
 
+// formatFloat formats f as a float literal. NaN and infinite values have
+// no literal form.
+func formatFloat(f float64) (string, bool) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", false
+	}
+
+	str := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.Contains(str, ".") {
+		str += ".0"
+	}
+	return str, true
+}
+
 func addFloatFunctions(ds *datastack) {
 
 	ds.FunctionMap["+"] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(2) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -35,6 +51,7 @@ func addFloatFunctions(ds *datastack) {
 
 	ds.FunctionMap["*"] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(2) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -43,6 +60,7 @@ func addFloatFunctions(ds *datastack) {
 
 	ds.FunctionMap["-"] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(2) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -50,7 +68,13 @@ func addFloatFunctions(ds *datastack) {
 	}
 
 	ds.FunctionMap["/"] = func(d DataStack, r RunSet, i Interpreter) {
-		if d.Lack(2) || d.Peek().(float64) == 0 {
+		if d.Lack(2) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		if d.Peek().(float64) == 0 {
+			r.Noop(DivideByZero)
 			return
 		}
 
@@ -61,7 +85,13 @@ func addFloatFunctions(ds *datastack) {
 	}
 
 	ds.FunctionMap["%"] = func(d DataStack, r RunSet, i Interpreter) {
-		if d.Lack(2) || d.Peek().(float64) == 0 {
+		if d.Lack(2) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		if d.Peek().(float64) == 0 {
+			r.Noop(DivideByZero)
 			return
 		}
 
@@ -74,6 +104,7 @@ func addFloatFunctions(ds *datastack) {
 
 	ds.FunctionMap["min"] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(2) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -89,6 +120,7 @@ func addFloatFunctions(ds *datastack) {
 
 	ds.FunctionMap["max"] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(2) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -104,6 +136,7 @@ func addFloatFunctions(ds *datastack) {
 
 	ds.FunctionMap[">"] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(2) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -115,6 +148,7 @@ func addFloatFunctions(ds *datastack) {
 
 	ds.FunctionMap["<"] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(2) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -126,6 +160,7 @@ func addFloatFunctions(ds *datastack) {
 
 	ds.FunctionMap["="] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(2) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -134,6 +169,7 @@ func addFloatFunctions(ds *datastack) {
 
 	ds.FunctionMap["fromboolean"] = func(d DataStack, r RunSet, i Interpreter) {
 		if r.Bad("boolean", 1) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -147,6 +183,7 @@ func addFloatFunctions(ds *datastack) {
 
 	ds.FunctionMap["frominteger"] = func(d DataStack, r RunSet, i Interpreter) {
 		if r.Bad("integer", 1) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -155,6 +192,7 @@ func addFloatFunctions(ds *datastack) {
 
 	ds.FunctionMap["sin"] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(1) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -163,6 +201,7 @@ func addFloatFunctions(ds *datastack) {
 
 	ds.FunctionMap["cos"] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(1) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -171,6 +210,7 @@ func addFloatFunctions(ds *datastack) {
 
 	ds.FunctionMap["tan"] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(1) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
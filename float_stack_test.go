@@ -175,3 +175,25 @@ func TestFloatStackConstructor(t *testing.T) {
 		})
 	})
 }
+
+func TestFormatFloat(t *testing.T) {
+	Convey("Given float values", t, func() {
+		Convey("Whole numbers keep a decimal point", func() {
+			str, ok := formatFloat(3)
+			So(ok, ShouldBeTrue)
+			So(str, ShouldEqual, "3.0")
+		})
+
+		Convey("Fractions are formatted without exponents", func() {
+			str, _ := formatFloat(-0.000125)
+			So(str, ShouldEqual, "-0.000125")
+		})
+
+		Convey("NaN and infinity have no literal", func() {
+			_, ok := formatFloat(math.NaN())
+			So(ok, ShouldBeFalse)
+			_, ok = formatFloat(math.Inf(1))
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
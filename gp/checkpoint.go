@@ -0,0 +1,206 @@
+package gp
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/asartalo/spogoto"
+	"math"
+	"os"
+)
+
+// Checkpoint is the saved state of an Engine and the Options of its
+// Interpreter. Options that are functions, like the Selection and
+// Variations, are not saved. An Engine restoring a Checkpoint keeps its
+// own.
+type Checkpoint struct {
+	Options     Options
+	Interpreter spogoto.Options
+	Generation  int
+	Evaluations int64
+	RandState   int64
+	Population  []IndividualState
+	Best        *IndividualState
+}
+
+// IndividualState is the saved state of an Individual.
+type IndividualState struct {
+	Code      spogoto.Code
+	Errors    []Float
+	Size      int64
+	Evaluated bool
+}
+
+// Float is a float64 that can be saved as JSON even when it is infinite or
+// NaN. These are saved as the strings "+Inf", "-Inf" and "NaN". Null is
+// read as +Inf.
+type Float float64
+
+// MarshalJSON encodes the Float as a number or as a string if it is not
+// finite.
+func (f Float) MarshalJSON() ([]byte, error) {
+	v := float64(f)
+	switch {
+	case math.IsInf(v, 1):
+		return []byte(`"+Inf"`), nil
+	case math.IsInf(v, -1):
+		return []byte(`"-Inf"`), nil
+	case math.IsNaN(v):
+		return []byte(`"NaN"`), nil
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes a Float encoded by MarshalJSON.
+func (f *Float) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case "null", `"+Inf"`:
+		*f = Float(math.Inf(1))
+	case `"-Inf"`:
+		*f = Float(math.Inf(-1))
+	case `"NaN"`:
+		*f = Float(math.NaN())
+	default:
+		var v float64
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		*f = Float(v)
+	}
+	return nil
+}
+
+// jsonOptions has the fields of Options without its JSON methods.
+type jsonOptions Options
+
+// MarshalJSON encodes the Options so that an infinite TargetError can be
+// saved.
+func (o Options) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		jsonOptions
+		TargetError Float
+	}{jsonOptions(o), Float(o.TargetError)})
+}
+
+// UnmarshalJSON decodes Options encoded by MarshalJSON.
+func (o *Options) UnmarshalJSON(data []byte) error {
+	aux := struct {
+		*jsonOptions
+		TargetError Float
+	}{jsonOptions: (*jsonOptions)(o)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	o.TargetError = float64(aux.TargetError)
+	return nil
+}
+
+// Checkpoint returns the state of the Engine. The Rand of the Engine must
+// be a SplitMix64 so that its state can be saved.
+func (e *Engine) Checkpoint() (Checkpoint, error) {
+	r, ok := e.Rand.(*spogoto.SplitMix64)
+	if !ok {
+		return Checkpoint{}, errors.New("gp: the Rand of the Engine is not a SplitMix64")
+	}
+
+	c := Checkpoint{
+		Options:     e.Options,
+		Interpreter: e.Interpreter.Config(),
+		Generation:  e.Generation,
+		Evaluations: e.Evaluations,
+		RandState:   r.State(),
+		Population:  []IndividualState{},
+	}
+	for _, ind := range e.Population {
+		c.Population = append(c.Population, individualState(ind))
+	}
+	if e.best != nil {
+		best := individualState(e.best)
+		c.Best = &best
+	}
+	return c, nil
+}
+
+// Restore sets the state of the Engine to the state of a Checkpoint. The
+// Interpreter is replaced by one with the saved Options and the stack
+// constructors, constants, hooks and Rand of the current Interpreter.
+func (e *Engine) Restore(c Checkpoint) {
+	selection, variations := e.Options.Selection, e.Options.Variations
+	e.Options = c.Options
+	e.Options.Selection, e.Options.Variations = selection, variations
+
+	current := e.Interpreter.Config()
+	options := c.Interpreter
+	options.StackConstructors = current.StackConstructors
+	options.Constants = current.Constants
+	options.Hooks = current.Hooks
+	options.Rand = current.Rand
+	e.Interpreter = spogoto.NewInterpreter(options)
+
+	e.Generation = c.Generation
+	e.Evaluations = c.Evaluations
+	e.Rand = spogoto.NewSplitMix64(c.RandState)
+
+	e.Population = Population{}
+	for _, state := range c.Population {
+		e.Population = append(e.Population, state.individual())
+	}
+	e.best = nil
+	if c.Best != nil {
+		e.best = c.Best.individual()
+	}
+}
+
+// Save writes a Checkpoint of the Engine to a file as JSON. The file is
+// replaced only after the Checkpoint has been written completely.
+func (e *Engine) Save(path string) error {
+	c, err := e.Checkpoint()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load restores the Engine from a Checkpoint file written by Save.
+func (e *Engine) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var c Checkpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return err
+	}
+	e.Restore(c)
+	return nil
+}
+
+func individualState(ind *Individual) IndividualState {
+	state := IndividualState{Code: ind.Code, Size: ind.Size, Evaluated: ind.Evaluated}
+	for _, e := range ind.Errors {
+		state.Errors = append(state.Errors, Float(e))
+	}
+	return state
+}
+
+func (state IndividualState) individual() *Individual {
+	ind := &Individual{Code: state.Code, Size: state.Size}
+	if !state.Evaluated {
+		return ind
+	}
+
+	values := []float64{}
+	for _, e := range state.Errors {
+		values = append(values, float64(e))
+	}
+	ind.SetErrors(values)
+	return ind
+}
@@ -0,0 +1,113 @@
+package gp
+
+import (
+	"github.com/asartalo/spogoto"
+	. "github.com/smartystreets/goconvey/convey"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpoint(t *testing.T) {
+	i := spogoto.NewInterpreter(spogoto.DefaultOptions)
+	dir, _ := os.MkdirTemp("", "gp")
+	defer os.RemoveAll(dir)
+
+	codes := func(p Population) []spogoto.Code {
+		c := []spogoto.Code{}
+		for _, ind := range p {
+			c = append(c, ind.Code)
+		}
+		return c
+	}
+
+	Convey("Given an Engine that saves checkpoints", t, func() {
+		path := filepath.Join(dir, "run.json")
+		options := testOptions()
+		options.TargetError = -1
+		options.MaxGenerations = 7
+		options.CheckpointEvery = 4
+		options.CheckpointPath = path
+		e := NewEngine(i, distanceFrom(20), options)
+		result := e.Run()
+
+		Convey("The checkpoint is saved", func() {
+			So(result.CheckpointError, ShouldBeNil)
+			_, err := os.Stat(path)
+			So(err, ShouldBeNil)
+		})
+
+		Convey("When a new Engine loads the checkpoint", func() {
+			resumed := NewEngine(i, distanceFrom(20), DefaultOptions)
+			resumed.Options.Variations = options.Variations
+			So(resumed.Load(path), ShouldBeNil)
+
+			Convey("It continues from the saved generation", func() {
+				So(resumed.Generation, ShouldEqual, 4)
+				So(resumed.Options.MaxGenerations, ShouldEqual, 7)
+				So(resumed.Options.PopulationSize, ShouldEqual, 20)
+			})
+
+			Convey("It evolves the same programs", func() {
+				again := resumed.Run()
+				So(again.Generation, ShouldEqual, result.Generation)
+				So(again.Evaluations, ShouldEqual, result.Evaluations)
+				So(again.Best.Code, ShouldResemble, result.Best.Code)
+				So(codes(resumed.Population), ShouldResemble, codes(e.Population))
+			})
+		})
+	})
+
+	Convey("Given a Checkpoint with infinite errors", t, func() {
+		e := NewEngine(i, distanceFrom(20), testOptions())
+		e.Population = Population{evaluated(1, math.Inf(1), math.Inf(-1)), {Code: spogoto.Code{"1"}}}
+		e.Options.TargetError = math.Inf(-1)
+		path := filepath.Join(dir, "inf.json")
+		So(e.Save(path), ShouldBeNil)
+
+		Convey("The errors are restored", func() {
+			restored := NewEngine(i, distanceFrom(20), testOptions())
+			So(restored.Load(path), ShouldBeNil)
+			So(restored.Population[0].Errors[0], ShouldEqual, 1)
+			So(math.IsInf(restored.Population[0].Errors[1], 1), ShouldBeTrue)
+			So(math.IsInf(restored.Population[0].Errors[2], -1), ShouldBeTrue)
+			So(math.IsInf(restored.Options.TargetError, -1), ShouldBeTrue)
+			So(restored.Population[1].Evaluated, ShouldBeFalse)
+		})
+	})
+
+	Convey("Given an Engine with an Interpreter with its own options", t, func() {
+		options := spogoto.DefaultOptions
+		options.IntegerRange = [2]int64{-50, 50}
+		options.LiteralChance = 0.8
+		options.TypeWeights = map[string]float64{"integer": 3}
+		e := NewEngine(spogoto.NewInterpreter(options), distanceFrom(20), testOptions())
+		path := filepath.Join(dir, "interpreter.json")
+		So(e.Save(path), ShouldBeNil)
+
+		Convey("The options of the Interpreter are restored", func() {
+			restored := NewEngine(i, distanceFrom(20), testOptions())
+			So(restored.Load(path), ShouldBeNil)
+			config := restored.Interpreter.Config()
+			So(config.IntegerRange, ShouldResemble, options.IntegerRange)
+			So(config.LiteralChance, ShouldEqual, 0.8)
+			So(config.TypeWeights, ShouldResemble, options.TypeWeights)
+			So(config.StackConstructors, ShouldHaveLength, len(options.StackConstructors))
+			So(restored.Interpreter.Parse(spogoto.Code{"integer.+"}), ShouldHaveLength, 1)
+		})
+	})
+
+	Convey("Engines without a SplitMix64 can't be saved", t, func() {
+		e := NewEngine(i, distanceFrom(20), testOptions())
+		e.Rand = rand.New(rand.NewSource(1))
+		_, err := e.Checkpoint()
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Missing checkpoints can't be loaded", t, func() {
+		e := NewEngine(i, distanceFrom(20), testOptions())
+		So(e.Load(filepath.Join(dir, "missing.json")), ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,310 @@
+package gp
+
+import (
+	"fmt"
+	"github.com/asartalo/spogoto"
+	"math"
+	"runtime"
+)
+
+// Mode is the way an Engine replaces the Individuals of its Population.
+type Mode int
+
+const (
+	// Generational replaces the whole Population each generation except
+	// for the elite Individuals.
+	Generational Mode = iota
+
+	// SteadyState replaces the worst Individual with each new child. A
+	// generation is as many children as the size of the Population.
+	SteadyState
+)
+
+// Termination describes why an Engine stopped.
+type Termination int
+
+const (
+	// NotTerminated is the Termination of an Engine that is still running.
+	NotTerminated Termination = iota
+
+	// TargetReached means an Individual's error was at or below
+	// Options.TargetError.
+	TargetReached
+
+	// BudgetExhausted means the number of evaluations reached
+	// Options.MaxEvaluations.
+	BudgetExhausted
+
+	// MaxGenerations means the number of generations reached
+	// Options.MaxGenerations.
+	MaxGenerations
+
+	// EmptyPopulation means there were no Individuals to evolve because
+	// Options.PopulationSize is less than 1.
+	EmptyPopulation
+)
+
+var terminationNames = map[Termination]string{
+	NotTerminated:   "not terminated",
+	TargetReached:   "target reached",
+	BudgetExhausted: "budget exhausted",
+	MaxGenerations:  "max generations",
+	EmptyPopulation: "empty population",
+}
+
+func (t Termination) String() string {
+	name, ok := terminationNames[t]
+	if !ok {
+		return fmt.Sprintf("Termination(%d)", int(t))
+	}
+	return name
+}
+
+// Options sets how an Engine evolves programs.
+type Options struct {
+
+	// PopulationSize is the number of Individuals in the Population
+	PopulationSize int
+
+	// InitialLength is the maximum length of the initial random programs
+	InitialLength int64
+
+	// MaxSize is the maximum size of programs. Children that are larger are
+	// replaced by a copy of their first parent. Zero means there is no
+	// limit.
+	MaxSize int64
+
+	// Mode is the way Individuals are replaced
+	Mode Mode
+
+	// Elitism is the number of best Individuals copied unchanged to the
+	// next generation in Generational mode
+	Elitism int
+
+	// MaxGenerations is the number of generations after which the Engine
+	// stops
+	MaxGenerations int
+
+	// TargetError is the error at or below which the Engine stops
+	TargetError float64
+
+	// MaxEvaluations is the number of evaluations after which the Engine
+	// stops. Zero means there is no limit.
+	MaxEvaluations int64
+
+	// Selection chooses the parents of children
+	Selection Selection `json:"-"`
+
+	// Variations create children from parents
+	Variations []Variation `json:"-"`
+
+	// Workers is the number of goroutines that evaluate Individuals
+	Workers int
+
+	// CheckpointEvery is the number of generations between saving
+	// checkpoints to CheckpointPath. Zero means no checkpoints are saved.
+	CheckpointEvery int
+
+	// CheckpointPath is the file checkpoints are saved to
+	CheckpointPath string
+}
+
+// DefaultOptions is the default set of options.
+var DefaultOptions = Options{
+	PopulationSize: 100,
+	InitialLength:  20,
+	Mode:           Generational,
+	Elitism:        1,
+	MaxGenerations: 50,
+	Selection:      Tournament(7),
+	Variations: []Variation{
+		{UniformMutation(0.1), 1, 0.45},
+		{OnePointCrossover(1), 2, 0.45},
+	},
+	Workers: runtime.NumCPU(),
+}
+
+// Result is the outcome of running an Engine. CheckpointError is the last
+// error that happened while saving a checkpoint.
+type Result struct {
+	Best            *Individual
+	Generation      int
+	Evaluations     int64
+	Termination     Termination
+	CheckpointError error
+}
+
+// Engine evolves a Population of programs to minimize the total error
+// returned by a Fitness function.
+type Engine struct {
+	Interpreter spogoto.Interpreter
+	Fitness     Fitness
+	Options     Options
+	Rand        spogoto.Rand
+	Population  Population
+	Generation  int
+	Evaluations int64
+	best        *Individual
+	evaluator   *spogoto.Evaluator
+}
+
+// NewEngine constructs a new Engine configured with options.
+func NewEngine(i spogoto.Interpreter, fitness Fitness, options Options) *Engine {
+	return &Engine{
+		Interpreter: i,
+		Fitness:     fitness,
+		Options:     options,
+		Rand:        spogoto.NewSplitMix64(1),
+	}
+}
+
+// Run evolves the Population until one of the termination criteria is
+// met. A random Population is generated if the Engine has none. The Best
+// Individual of the Result is the best one evaluated during the run.
+func (e *Engine) Run() Result {
+	e.evaluator = spogoto.NewEvaluator(e.Interpreter, e.Options.Workers, 0)
+	if e.Population == nil {
+		length := e.Options.InitialLength
+		if e.Options.MaxSize > 0 && length > e.Options.MaxSize {
+			length = e.Options.MaxSize
+		}
+		e.Population = NewPopulation(e.Interpreter, e.Rand, e.Options.PopulationSize, length)
+	}
+	if len(e.Population) == 0 {
+		return Result{nil, e.Generation, e.Evaluations, EmptyPopulation, nil}
+	}
+	unevaluated := Population{}
+	for _, ind := range e.Population {
+		if !ind.Evaluated {
+			unevaluated = append(unevaluated, ind)
+		}
+	}
+	e.evaluate(unevaluated)
+
+	var checkpointError error
+	for {
+		if t := e.termination(); t != NotTerminated {
+			return Result{e.best, e.Generation, e.Evaluations, t, checkpointError}
+		}
+
+		if e.Options.Mode == SteadyState {
+			e.steadyStateGeneration()
+		} else {
+			e.generation()
+		}
+		e.Generation++
+
+		if e.Options.CheckpointEvery > 0 && e.Generation%e.Options.CheckpointEvery == 0 {
+			if err := e.Save(e.Options.CheckpointPath); err != nil {
+				checkpointError = err
+			}
+		}
+	}
+}
+
+// termination returns the criterion that stops the Engine, if any.
+func (e *Engine) termination() Termination {
+	if e.best != nil && e.best.Error <= e.Options.TargetError {
+		return TargetReached
+	}
+	if e.budgetExhausted() {
+		return BudgetExhausted
+	}
+	if e.Generation >= e.Options.MaxGenerations {
+		return MaxGenerations
+	}
+	return NotTerminated
+}
+
+func (e *Engine) budgetExhausted() bool {
+	return e.Options.MaxEvaluations > 0 && e.Evaluations >= e.Options.MaxEvaluations
+}
+
+// evaluate sets the errors of Individuals concurrently. Only as many
+// Individuals as the budget allows are evaluated. It returns false if
+// some Individuals were not evaluated.
+func (e *Engine) evaluate(p Population) bool {
+	if len(p) == 0 {
+		return true
+	}
+	if e.Options.MaxEvaluations > 0 {
+		remaining := e.Options.MaxEvaluations - e.Evaluations
+		if remaining < int64(len(p)) {
+			e.evaluate(p[:remaining])
+			return false
+		}
+	}
+
+	e.evaluator.Seed = e.Rand.Int63n(math.MaxInt64)
+	e.evaluator.Map(len(p), func(k int, i spogoto.Interpreter) {
+		p[k].SetErrors(e.Fitness(i, p[k].Code))
+		p[k].Size = Size(i, p[k].Code)
+	})
+
+	for _, ind := range p {
+		e.Evaluations++
+		if e.best == nil || ind.Error < e.best.Error {
+			e.best = ind
+		}
+	}
+	return true
+}
+
+// generation replaces the Population with a new one. The Population is
+// kept if the budget runs out before the new one is complete.
+func (e *Engine) generation() {
+	next := Population{}
+	for _, ind := range e.Population.Sorted() {
+		if len(next) >= e.Options.Elitism {
+			break
+		}
+		next = append(next, ind)
+	}
+
+	children := Population{}
+	for len(next)+len(children) < e.Options.PopulationSize {
+		children = append(children, &Individual{Code: e.breed()})
+	}
+	if e.evaluate(children) {
+		e.Population = append(next, children...)
+	}
+}
+
+// steadyStateGeneration replaces the worst Individual of the Population
+// with each new child.
+func (e *Engine) steadyStateGeneration() {
+	for k := 0; k < e.Options.PopulationSize; k++ {
+		child := &Individual{Code: e.breed()}
+		if !e.evaluate(Population{child}) {
+			return
+		}
+		worst := 0
+		for idx, ind := range e.Population {
+			if ind.Error >= e.Population[worst].Error {
+				worst = idx
+			}
+		}
+		e.Population[worst] = child
+	}
+}
+
+// breed creates a child using a randomly chosen Variation.
+func (e *Engine) breed() spogoto.Code {
+	chance := e.Rand.Float64()
+	for _, v := range e.Options.Variations {
+		if chance < v.Rate {
+			parents := []spogoto.Code{}
+			for k := 0; k < v.Parents; k++ {
+				parents = append(parents, e.Options.Selection(e.Population, e.Rand).Code)
+			}
+			child := v.Operator(parents, e.Interpreter, e.Rand)
+			if e.Options.MaxSize > 0 && Size(e.Interpreter, child) > e.Options.MaxSize {
+				return append(spogoto.Code{}, parents[0]...)
+			}
+			return child
+		}
+		chance -= v.Rate
+	}
+
+	return append(spogoto.Code{}, e.Options.Selection(e.Population, e.Rand).Code...)
+}
@@ -0,0 +1,184 @@
+package gp
+
+import (
+	"github.com/asartalo/spogoto"
+	. "github.com/smartystreets/goconvey/convey"
+	"math"
+	"testing"
+)
+
+// distanceFrom returns a Fitness that is the distance of the top integer
+// from target.
+func distanceFrom(target int64) Fitness {
+	return func(i spogoto.Interpreter, code spogoto.Code) []float64 {
+		r := i.Run(code, spogoto.StackState{})
+		top, ok := r.TopInteger()
+		if !ok {
+			return []float64{1000}
+		}
+		return []float64{math.Abs(float64(top - target))}
+	}
+}
+
+func testOptions() Options {
+	options := DefaultOptions
+	options.PopulationSize = 20
+	options.InitialLength = 10
+	options.MaxGenerations = 5
+	return options
+}
+
+func TestEngine(t *testing.T) {
+	i := spogoto.NewInterpreter(spogoto.DefaultOptions)
+
+	Convey("Given an Engine with a target that can't be reached", t, func() {
+		options := testOptions()
+		options.TargetError = -1
+		e := NewEngine(i, distanceFrom(20), options)
+
+		Convey("When run", func() {
+			result := e.Run()
+
+			Convey("It stops at the max generations", func() {
+				So(result.Termination, ShouldEqual, MaxGenerations)
+				So(result.Generation, ShouldEqual, 5)
+				So(e.Population, ShouldHaveLength, 20)
+			})
+
+			Convey("It evaluates every child of every generation", func() {
+				So(result.Evaluations, ShouldEqual, 20+5*19)
+			})
+
+			Convey("The best Individual is the best evaluated one", func() {
+				So(result.Best.Error, ShouldBeLessThanOrEqualTo, e.Population.Best().Error)
+			})
+
+			Convey("Elitism keeps the best Individual", func() {
+				So(e.Population, ShouldContain, e.Population.Best())
+				So(e.Population.Best().Error, ShouldEqual, result.Best.Error)
+			})
+		})
+	})
+
+	Convey("Given an Engine with an empty tournament", t, func() {
+		options := testOptions()
+		options.Selection = Tournament(0)
+		e := NewEngine(i, distanceFrom(20), options)
+
+		Convey("It still breeds children", func() {
+			So(func() { e.Run() }, ShouldNotPanic)
+		})
+	})
+
+	Convey("Given an Engine with a perfect Individual", t, func() {
+		e := NewEngine(i, distanceFrom(3), testOptions())
+		e.Population = Population{
+			{Code: spogoto.CodeFromString("1")},
+			{Code: spogoto.CodeFromString("3")},
+		}
+
+		Convey("It stops when the target is reached", func() {
+			result := e.Run()
+			So(result.Termination, ShouldEqual, TargetReached)
+			So(result.Generation, ShouldEqual, 0)
+			So(result.Best.Code, ShouldResemble, spogoto.CodeFromString("3"))
+		})
+	})
+
+	Convey("Given an Engine with an evaluation budget", t, func() {
+		options := testOptions()
+		options.TargetError = -1
+		options.MaxEvaluations = 30
+		e := NewEngine(i, distanceFrom(20), options)
+
+		Convey("It stops when the budget is exhausted", func() {
+			result := e.Run()
+			So(result.Termination, ShouldEqual, BudgetExhausted)
+			So(result.Evaluations, ShouldEqual, 30)
+		})
+	})
+
+	Convey("Given an Engine without Individuals", t, func() {
+		options := testOptions()
+		options.PopulationSize = 0
+		e := NewEngine(i, distanceFrom(20), options)
+
+		Convey("It stops without evolving anything", func() {
+			result := e.Run()
+			So(result.Termination, ShouldEqual, EmptyPopulation)
+			So(result.Best, ShouldBeNil)
+			So(result.Evaluations, ShouldEqual, 0)
+		})
+	})
+
+	Convey("Given a steady-state Engine", t, func() {
+		options := testOptions()
+		options.Mode = SteadyState
+		options.TargetError = -1
+		e := NewEngine(i, distanceFrom(20), options)
+
+		Convey("When run", func() {
+			result := e.Run()
+
+			Convey("Each generation evaluates as many children as the Population size", func() {
+				So(result.Termination, ShouldEqual, MaxGenerations)
+				So(result.Evaluations, ShouldEqual, 20+5*20)
+				So(e.Population, ShouldHaveLength, 20)
+			})
+
+			Convey("The best Individual is never replaced", func() {
+				So(e.Population.Best().Error, ShouldEqual, result.Best.Error)
+			})
+		})
+	})
+}
+
+func TestEngineMaxSize(t *testing.T) {
+	Convey("Given an Engine with a max program size", t, func() {
+		i := spogoto.NewInterpreter(spogoto.DefaultOptions)
+		options := testOptions()
+		options.TargetError = -1
+		options.InitialLength = 30
+		options.MaxSize = 8
+		options.Variations = []Variation{
+			{UniformInsertion(0.5), 1, 0.5},
+			{OnePointCrossover(1), 2, 0.5},
+		}
+		e := NewEngine(i, distanceFrom(20), options)
+		e.Run()
+
+		Convey("No program is larger than the max size", func() {
+			for _, ind := range e.Population {
+				So(ind.Size, ShouldBeLessThanOrEqualTo, 8)
+				So(ind.Size, ShouldEqual, Size(i, ind.Code))
+			}
+		})
+	})
+}
+
+func TestTermination(t *testing.T) {
+	Convey("Terminations have names", t, func() {
+		So(TargetReached.String(), ShouldEqual, "target reached")
+		So(Termination(99).String(), ShouldEqual, "Termination(99)")
+	})
+}
+
+func TestEngineReproducibility(t *testing.T) {
+	Convey("Given Engines with the same seed and different workers", t, func() {
+		i := spogoto.NewInterpreter(spogoto.DefaultOptions)
+		run := func(workers int) Result {
+			options := testOptions()
+			options.TargetError = -1
+			options.Workers = workers
+			return NewEngine(i, distanceFrom(20), options).Run()
+		}
+
+		Convey("They evolve the same programs", func() {
+			r1 := run(1)
+			r4 := run(4)
+			So(r4.Best.Code, ShouldResemble, r1.Best.Code)
+			So(r4.Best.Errors, ShouldResemble, r1.Best.Errors)
+			So(r4.Evaluations, ShouldEqual, r1.Evaluations)
+		})
+	})
+}
@@ -0,0 +1,35 @@
+package gp
+
+import (
+	"github.com/asartalo/spogoto"
+)
+
+// Fitness returns the errors of code on each test case. Lower errors are
+// better and an error of zero is a perfect result. A Fitness is called
+// from many goroutines at the same time and must only use the Interpreter
+// it is given to generate random values.
+type Fitness func(i spogoto.Interpreter, code spogoto.Code) []float64
+
+// Case is a test case for a program. The program is run with State and
+// Inputs and its result is compared to Expected.
+type Case struct {
+	State    spogoto.StackState
+	Inputs   spogoto.Inputs
+	Expected spogoto.Elements
+}
+
+// CaseError returns the error of the result of running a program on c.
+type CaseError func(c Case, r spogoto.RunSet) float64
+
+// CaseFitness returns a Fitness that runs code on each of the cases and
+// measures the result with caseError.
+func CaseFitness(cases []Case, caseError CaseError) Fitness {
+	return func(i spogoto.Interpreter, code spogoto.Code) []float64 {
+		errors := []float64{}
+		for _, c := range cases {
+			r := i.RunWithInputs(code, c.State, c.Inputs)
+			errors = append(errors, caseError(c, r))
+		}
+		return errors
+	}
+}
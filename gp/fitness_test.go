@@ -0,0 +1,41 @@
+package gp
+
+import (
+	"github.com/asartalo/spogoto"
+	. "github.com/smartystreets/goconvey/convey"
+	"math"
+	"testing"
+)
+
+func TestCaseFitness(t *testing.T) {
+	Convey("Given test cases for doubling an integer", t, func() {
+		options := spogoto.DefaultOptions
+		options.Inputs = 1
+		i := spogoto.NewInterpreter(options)
+		cases := []Case{
+			{Inputs: spogoto.Inputs{int64(1)}, Expected: spogoto.Elements{int64(2)}},
+			{Inputs: spogoto.Inputs{int64(3)}, Expected: spogoto.Elements{int64(6)}},
+			{
+				State:    spogoto.StackState{"integer": spogoto.Elements{int64(5)}},
+				Expected: spogoto.Elements{int64(10)},
+			},
+		}
+		fitness := CaseFitness(cases, func(c Case, r spogoto.RunSet) float64 {
+			top, ok := r.TopInteger()
+			if !ok {
+				return 100
+			}
+			return math.Abs(float64(top - c.Expected[0].(int64)))
+		})
+
+		Convey("It measures the error on each case", func() {
+			So(fitness(i, spogoto.CodeFromString("in.0 integer.dup integer.+")), ShouldResemble, []float64{0, 0, 0})
+			So(fitness(i, spogoto.CodeFromString("integer.dup integer.+")), ShouldResemble, []float64{100, 100, 0})
+		})
+
+		Convey("The states of the cases are not changed", func() {
+			fitness(i, spogoto.CodeFromString("integer.pop"))
+			So(cases[2].State["integer"], ShouldResemble, spogoto.Elements{int64(5)})
+		})
+	})
+}
@@ -0,0 +1,94 @@
+// Package gp evolves Spogoto code using genetic programming.
+package gp
+
+import (
+	"github.com/asartalo/spogoto"
+	"math"
+	"sort"
+)
+
+// Individual is a program in a Population together with its errors on
+// each test case, their total and the size of the program.
+type Individual struct {
+	Code      spogoto.Code
+	Errors    []float64
+	Error     float64
+	Size      int64
+	Evaluated bool
+}
+
+// Size returns the size of code. It is the size of the parsed code so
+// that unknown items are not counted.
+func Size(i spogoto.Interpreter, code spogoto.Code) int64 {
+	return i.Parse(code).Size()
+}
+
+// SetErrors sets the errors of the Individual and marks it as evaluated.
+func (ind *Individual) SetErrors(errors []float64) {
+	ind.Errors = make([]float64, len(errors))
+	ind.Error = 0
+	for k, e := range errors {
+		ind.Errors[k] = normalError(e)
+		ind.Error += ind.Errors[k]
+	}
+	ind.Evaluated = true
+}
+
+// Population is a group of Individuals.
+type Population []*Individual
+
+// NewPopulation generates a Population of size random programs. The length
+// of each program is between 1 and maxLength.
+func NewPopulation(i spogoto.Interpreter, r spogoto.Rand, size int, maxLength int64) Population {
+	p := Population{}
+	for k := 0; k < size; k++ {
+		length := int64(1)
+		if maxLength > 1 {
+			length += r.Int63n(maxLength)
+		}
+		p = append(p, &Individual{Code: i.WithRand(r).RandomCode(length)})
+	}
+	return p
+}
+
+// Best returns the evaluated Individual with the lowest error or nil if
+// no Individual has been evaluated.
+func (p Population) Best() *Individual {
+	var best *Individual
+	for _, ind := range p {
+		if ind.Evaluated && (best == nil || ind.Error < best.Error) {
+			best = ind
+		}
+	}
+	return best
+}
+
+// Sorted returns a copy of the Population sorted from the lowest error to
+// the highest. Individuals with equal errors keep their order.
+func (p Population) Sorted() Population {
+	sorted := append(Population{}, p...)
+	sort.Stable(byError(sorted))
+	return sorted
+}
+
+type byError Population
+
+func (p byError) Len() int {
+	return len(p)
+}
+
+func (p byError) Less(a, b int) bool {
+	return p[a].Error < p[b].Error
+}
+
+func (p byError) Swap(a, b int) {
+	p[a], p[b] = p[b], p[a]
+}
+
+// normalError converts errors that can't be compared to the highest error.
+func normalError(e float64) float64 {
+	if math.IsNaN(e) {
+		return math.Inf(1)
+	}
+	return e
+}
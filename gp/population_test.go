@@ -0,0 +1,69 @@
+package gp
+
+import (
+	"github.com/asartalo/spogoto"
+	. "github.com/smartystreets/goconvey/convey"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestPopulation(t *testing.T) {
+	Convey("Given a new Population", t, func() {
+		i := spogoto.NewInterpreter(spogoto.DefaultOptions)
+		p := NewPopulation(i, rand.New(rand.NewSource(1)), 10, 5)
+
+		Convey("It has random programs of up to the maximum length", func() {
+			So(p, ShouldHaveLength, 10)
+			for _, ind := range p {
+				So(len(ind.Code), ShouldBeBetweenOrEqual, 1, 5)
+				So(ind.Evaluated, ShouldBeFalse)
+			}
+		})
+
+		Convey("It has no best Individual before evaluation", func() {
+			So(p.Best(), ShouldBeNil)
+		})
+	})
+
+	Convey("Given an evaluated Population", t, func() {
+		p := Population{
+			{Code: spogoto.Code{"a"}, Error: 3, Evaluated: true},
+			{Code: spogoto.Code{"b"}, Error: 1, Evaluated: true},
+			{Code: spogoto.Code{"c"}, Error: 3, Evaluated: true},
+			{Code: spogoto.Code{"d"}, Error: 2, Evaluated: true},
+		}
+
+		Convey("The best Individual has the lowest error", func() {
+			So(p.Best(), ShouldEqual, p[1])
+		})
+
+		Convey("Sorting keeps the order of equal errors", func() {
+			sorted := p.Sorted()
+			So(sorted, ShouldResemble, Population{p[1], p[3], p[0], p[2]})
+			So(p[0].Code, ShouldResemble, spogoto.Code{"a"})
+		})
+	})
+
+	Convey("The size of a program is the size of the parsed code", t, func() {
+		i := spogoto.NewInterpreter(spogoto.DefaultOptions)
+		So(Size(i, spogoto.CodeFromString("1 foo.bar (integer.+ 2)")), ShouldEqual, 4)
+	})
+
+	Convey("Given errors of an Individual", t, func() {
+		ind := &Individual{}
+		ind.SetErrors([]float64{1, 2.5, 0})
+
+		Convey("The error is their total", func() {
+			So(ind.Evaluated, ShouldBeTrue)
+			So(ind.Errors, ShouldResemble, []float64{1, 2.5, 0})
+			So(ind.Error, ShouldEqual, 3.5)
+		})
+
+		Convey("NaN errors are the highest errors", func() {
+			ind.SetErrors([]float64{1, math.NaN()})
+			So(math.IsInf(ind.Errors[1], 1), ShouldBeTrue)
+			So(math.IsInf(ind.Error, 1), ShouldBeTrue)
+		})
+	})
+}
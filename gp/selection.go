@@ -0,0 +1,245 @@
+package gp
+
+import (
+	"github.com/asartalo/spogoto"
+	"math"
+	"sort"
+	"sync"
+)
+
+// Selection chooses a parent from an evaluated Population. Selections
+// return nil if the Population is empty.
+type Selection func(p Population, r spogoto.Rand) *Individual
+
+// Tournament returns a Selection that picks the Individual with the lowest
+// error among size randomly chosen Individuals. A size less than 1 is
+// treated as 1.
+func Tournament(size int) Selection {
+	return func(p Population, r spogoto.Rand) *Individual {
+		if len(p) == 0 {
+			return nil
+		}
+		var winner *Individual
+		for k := 0; k < size || winner == nil; k++ {
+			ind := p[r.Int63n(int64(len(p)))]
+			if winner == nil || ind.Error < winner.Error {
+				winner = ind
+			}
+		}
+		return winner
+	}
+}
+
+// ParsimonyTournament returns a Selection like Tournament where the error
+// of each Individual is increased by parsimony times its size. This
+// favors smaller programs among those with similar errors. A size less
+// than 1 is treated as 1.
+func ParsimonyTournament(size int, parsimony float64) Selection {
+	return func(p Population, r spogoto.Rand) *Individual {
+		if len(p) == 0 {
+			return nil
+		}
+		var winner *Individual
+		var lowest float64
+		for k := 0; k < size || winner == nil; k++ {
+			ind := p[r.Int63n(int64(len(p)))]
+			penalized := ind.Error + parsimony*float64(ind.Size)
+			if winner == nil || penalized < lowest {
+				winner, lowest = ind, penalized
+			}
+		}
+		return winner
+	}
+}
+
+// FitnessProportionate returns a Selection that picks an Individual with a
+// probability proportional to 1 / (1 + error). When there are negative
+// errors, the errors are shifted so that the lowest one is 0.
+func FitnessProportionate() Selection {
+	return func(p Population, r spogoto.Rand) *Individual {
+		if len(p) == 0 {
+			return nil
+		}
+		lowest := 0.0
+		for _, ind := range p {
+			lowest = math.Min(lowest, ind.Error)
+		}
+
+		weights := make([]float64, len(p))
+		total := 0.0
+		for k, ind := range p {
+			shifted := ind.Error - lowest
+			if math.IsNaN(shifted) {
+				// Both are -Inf
+				shifted = 0
+			}
+			weights[k] = 1 / (1 + shifted)
+			total += weights[k]
+		}
+		if total == 0 {
+			return p[r.Int63n(int64(len(p)))]
+		}
+
+		chance := r.Float64() * total
+		for k, w := range weights {
+			if chance < w {
+				return p[k]
+			}
+			chance -= w
+		}
+		return p[len(p)-1]
+	}
+}
+
+// Lexicase returns a Selection that filters the Population one test case
+// at a time, in random order, keeping only the Individuals with the lowest
+// error on the case. A random Individual among those left is picked. When
+// the error vectors have different lengths, only the cases that every
+// Individual has an error for are used.
+func Lexicase() Selection {
+	return func(p Population, r spogoto.Rand) *Individual {
+		if len(p) == 0 {
+			return nil
+		}
+		return lexicase(p, r, func(int) float64 { return 0 })
+	}
+}
+
+// EpsilonLexicase returns a Selection like Lexicase that also keeps the
+// Individuals whose error on a case is within epsilon of the lowest.
+func EpsilonLexicase(epsilon float64) Selection {
+	return func(p Population, r spogoto.Rand) *Individual {
+		if len(p) == 0 {
+			return nil
+		}
+		return lexicase(p, r, func(int) float64 { return epsilon })
+	}
+}
+
+// AutoEpsilonLexicase returns a Selection like EpsilonLexicase where the
+// epsilon of each case is the median absolute deviation of the errors of
+// the Population on the case. The epsilons are computed again only when
+// the Individuals of the Population change.
+func AutoEpsilonLexicase() Selection {
+	var mutex sync.Mutex
+	var population Population
+	var epsilons []float64
+	return func(p Population, r spogoto.Rand) *Individual {
+		if len(p) == 0 {
+			return nil
+		}
+
+		mutex.Lock()
+		if !samePopulation(population, p) {
+			population = append(Population{}, p...)
+			epsilons = caseEpsilons(p)
+		}
+		current := epsilons
+		mutex.Unlock()
+
+		return lexicase(p, r, func(c int) float64 { return current[c] })
+	}
+}
+
+// caseEpsilons returns the median absolute deviation of the errors of the
+// Population on each case.
+func caseEpsilons(p Population) []float64 {
+	epsilons := []float64{}
+	for c := 0; c < caseCount(p); c++ {
+		errors := []float64{}
+		for _, ind := range p {
+			errors = append(errors, ind.Errors[c])
+		}
+		epsilons = append(epsilons, medianAbsoluteDeviation(errors))
+	}
+	return epsilons
+}
+
+// caseCount returns the number of cases that every Individual of the
+// Population has an error for.
+func caseCount(p Population) int {
+	count := len(p[0].Errors)
+	for _, ind := range p {
+		if len(ind.Errors) < count {
+			count = len(ind.Errors)
+		}
+	}
+	return count
+}
+
+// samePopulation returns true if a and b have the same Individuals in the
+// same order.
+func samePopulation(a, b Population) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if a[k] != b[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// lexicase selects an Individual keeping the Individuals whose error on
+// case c is within epsilon(c) of the lowest.
+func lexicase(p Population, r spogoto.Rand, epsilon func(int) float64) *Individual {
+	candidates := append(Population{}, p...)
+	for _, c := range shuffledCases(caseCount(p), r) {
+		if len(candidates) == 1 {
+			break
+		}
+
+		lowest := math.Inf(1)
+		for _, ind := range candidates {
+			lowest = math.Min(lowest, ind.Errors[c])
+		}
+
+		kept := Population{}
+		for _, ind := range candidates {
+			if ind.Errors[c] <= lowest+epsilon(c) {
+				kept = append(kept, ind)
+			}
+		}
+		candidates = kept
+	}
+	return candidates[r.Int63n(int64(len(candidates)))]
+}
+
+// shuffledCases returns the indices of n test cases in random order.
+func shuffledCases(n int, r spogoto.Rand) []int {
+	cases := make([]int, n)
+	for k := range cases {
+		cases[k] = k
+	}
+	for k := n - 1; k > 0; k-- {
+		j := r.Int63n(int64(k + 1))
+		cases[k], cases[j] = cases[j], cases[k]
+	}
+	return cases
+}
+
+// median returns the median of values.
+func median(values []float64) float64 {
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// medianAbsoluteDeviation returns the median of the absolute deviations
+// of values from their median.
+func medianAbsoluteDeviation(values []float64) float64 {
+	m := median(values)
+	if math.IsInf(m, 1) {
+		return 0
+	}
+	deviations := []float64{}
+	for _, v := range values {
+		deviations = append(deviations, math.Abs(v-m))
+	}
+	return median(deviations)
+}
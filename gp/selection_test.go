@@ -0,0 +1,125 @@
+package gp
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// evaluated creates an evaluated Individual with errors.
+func evaluated(errors ...float64) *Individual {
+	ind := &Individual{}
+	ind.SetErrors(errors)
+	return ind
+}
+
+// picks counts how many times each Individual is selected.
+func picks(s Selection, p Population, times int) map[*Individual]int {
+	r := rand.New(rand.NewSource(1))
+	counts := map[*Individual]int{}
+	for k := 0; k < times; k++ {
+		counts[s(p, r)]++
+	}
+	return counts
+}
+
+func TestSelection(t *testing.T) {
+	Convey("Given an evaluated Population", t, func() {
+		p := Population{
+			evaluated(3, 0, 0),
+			evaluated(0, 3, 0),
+			evaluated(1, 1, 0),
+			evaluated(2, 2, 2),
+		}
+
+		Convey("A tournament of one picks any Individual", func() {
+			So(picks(Tournament(1), p, 100), ShouldHaveLength, 4)
+		})
+
+		Convey("A tournament smaller than one picks an Individual", func() {
+			So(picks(Tournament(0), p, 100), ShouldHaveLength, 4)
+			So(picks(Tournament(-1), p, 100), ShouldHaveLength, 4)
+			So(picks(ParsimonyTournament(0, 1), p, 100), ShouldHaveLength, 4)
+		})
+
+		Convey("A large tournament picks the best Individual", func() {
+			So(picks(Tournament(50), p, 10), ShouldResemble, map[*Individual]int{p[2]: 10})
+		})
+
+		Convey("A parsimony tournament prefers smaller programs", func() {
+			p[2].Size = 20
+			p[3].Size = 1
+			p[1].Size = 2
+			So(picks(ParsimonyTournament(50, 0), p, 10), ShouldResemble, map[*Individual]int{p[2]: 10})
+			So(picks(ParsimonyTournament(50, 1), p, 10), ShouldResemble, map[*Individual]int{p[0]: 10})
+		})
+
+		Convey("Fitness proportionate selection prefers lower errors", func() {
+			counts := picks(FitnessProportionate(), p, 1000)
+			So(counts[p[2]], ShouldBeGreaterThan, counts[p[3]])
+		})
+
+		Convey("Fitness proportionate selection works with infinite errors", func() {
+			worst := Population{evaluated(math.Inf(1)), evaluated(math.Inf(1))}
+			So(picks(FitnessProportionate(), worst, 100), ShouldHaveLength, 2)
+		})
+
+		Convey("Fitness proportionate selection works with negative errors", func() {
+			negative := Population{evaluated(-5), evaluated(-2), evaluated(10)}
+			counts := picks(FitnessProportionate(), negative, 1000)
+			So(counts[negative[0]], ShouldBeGreaterThan, counts[negative[1]])
+			So(counts[negative[1]], ShouldBeGreaterThan, counts[negative[2]])
+			So(counts[negative[2]], ShouldBeGreaterThan, 0)
+		})
+
+		Convey("Lexicase picks specialists that are best on some case", func() {
+			counts := picks(Lexicase(), p, 100)
+			So(counts, ShouldHaveLength, 2)
+			So(counts[p[0]], ShouldBeGreaterThan, 0)
+			So(counts[p[1]], ShouldBeGreaterThan, 0)
+		})
+
+		Convey("Epsilon lexicase also keeps errors near the lowest", func() {
+			So(picks(EpsilonLexicase(1), p, 100), ShouldResemble, map[*Individual]int{p[2]: 100})
+		})
+
+		Convey("Automatic epsilon lexicase uses the deviation of each case", func() {
+			counts := picks(AutoEpsilonLexicase(), p, 100)
+			So(counts[p[2]], ShouldBeGreaterThan, 0)
+			So(counts[p[3]], ShouldEqual, 0)
+		})
+	})
+
+	Convey("Automatic epsilon lexicase follows changes to the Population", t, func() {
+		s := AutoEpsilonLexicase()
+		p := Population{evaluated(0), evaluated(10), evaluated(20)}
+		So(picks(s, p, 100), ShouldHaveLength, 2)
+		p[1], p[2] = evaluated(3), evaluated(3)
+		So(picks(s, p, 100), ShouldResemble, map[*Individual]int{p[0]: 100})
+	})
+
+	Convey("Lexicase selections use the cases every Individual has", t, func() {
+		p := Population{evaluated(5, 0), evaluated(0), evaluated(9, 0, 0)}
+		for _, s := range []Selection{Lexicase(), EpsilonLexicase(0), AutoEpsilonLexicase()} {
+			So(picks(s, p, 100), ShouldResemble, map[*Individual]int{p[1]: 100})
+		}
+		So(caseEpsilons(p), ShouldHaveLength, 1)
+	})
+
+	Convey("Selections from an empty Population pick nothing", t, func() {
+		selections := []Selection{
+			Tournament(3), ParsimonyTournament(3, 1), FitnessProportionate(),
+			Lexicase(), EpsilonLexicase(1), AutoEpsilonLexicase(),
+		}
+		for _, s := range selections {
+			So(s(Population{}, rand.New(rand.NewSource(1))), ShouldBeNil)
+		}
+	})
+
+	Convey("The median absolute deviation measures spread", t, func() {
+		So(medianAbsoluteDeviation([]float64{1, 1, 2, 2, 4, 6, 9}), ShouldEqual, 1)
+		So(medianAbsoluteDeviation([]float64{3, 0}), ShouldEqual, 1.5)
+		So(medianAbsoluteDeviation([]float64{math.Inf(1), math.Inf(1), 1}), ShouldEqual, 0)
+	})
+}
@@ -0,0 +1,172 @@
+package gp
+
+import (
+	"github.com/asartalo/spogoto"
+)
+
+// Simplify returns the simplest program found that has the same errors as
+// code. It removes each item that can be removed one at a time and then
+// tries steps random changes that remove several items, unwrap a block or
+// replace a few items with a literal. Only changes that keep the errors the
+// same and make the program shorter, or as long with fewer functions, are
+// kept. The Fitness is always given an Interpreter with a Rand seeded the
+// same way so that random Instructions don't change the errors.
+func Simplify(i spogoto.Interpreter, fitness Fitness, code spogoto.Code, steps int, r spogoto.Rand) spogoto.Code {
+	errors := func(c spogoto.Code) []float64 {
+		ind := &Individual{}
+		ind.SetErrors(fitness(i.WithRand(spogoto.NewSplitMix64(1)), c))
+		return ind.Errors
+	}
+	target := errors(code)
+	same := func(c spogoto.Code) bool {
+		return equalErrors(errors(c), target)
+	}
+
+	best := removeEach(append(spogoto.Code{}, code...), same)
+	for k := 0; k < steps && len(best) > 0; k++ {
+		var candidate spogoto.Code
+		chance := r.Float64()
+		if chance < 0.5 {
+			candidate = removeRandom(best, 1+r.Int63n(3), r)
+		} else if chance < 0.75 {
+			candidate = unwrapRandom(best, r)
+		} else {
+			candidate = replaceRandom(i, best, 1+r.Int63n(3), r)
+		}
+		if simpler(i, candidate, best) && same(candidate) {
+			best = candidate
+		}
+	}
+	return removeEach(best, same)
+}
+
+// removeEach removes each item of code, from last to first, if the code
+// without it is still the same.
+func removeEach(code spogoto.Code, same func(spogoto.Code) bool) spogoto.Code {
+	for k := len(code) - 1; k >= 0; k-- {
+		candidate := append(append(spogoto.Code{}, code[:k]...), code[k+1:]...)
+		if same(candidate) {
+			code = candidate
+		}
+	}
+	return code
+}
+
+// removeRandom returns a copy of code with up to n random items removed.
+func removeRandom(code spogoto.Code, n int64, r spogoto.Rand) spogoto.Code {
+	candidate := append(spogoto.Code{}, code...)
+	for k := int64(0); k < n && len(candidate) > 0; k++ {
+		idx := r.Int63n(int64(len(candidate)))
+		candidate = append(candidate[:idx], candidate[idx+1:]...)
+	}
+	return candidate
+}
+
+// unwrapRandom returns a copy of code with the parentheses of a random
+// block removed. Code without blocks is returned unchanged.
+func unwrapRandom(code spogoto.Code, r spogoto.Rand) spogoto.Code {
+	opens := []int{}
+	for k, item := range code {
+		if item == "(" {
+			opens = append(opens, k)
+		}
+	}
+	if len(opens) == 0 {
+		return code
+	}
+
+	start := opens[r.Int63n(int64(len(opens)))]
+	depth := 0
+	candidate := spogoto.Code{}
+	for k, item := range code {
+		if k == start {
+			depth = 1
+			continue
+		}
+		if depth > 0 {
+			if item == "(" {
+				depth++
+			} else if item == ")" {
+				depth--
+				if depth == 0 {
+					continue
+				}
+			}
+		}
+		candidate = append(candidate, item)
+	}
+	return candidate
+}
+
+// replaceRandom returns a copy of code where up to n items that follow a
+// random item are replaced with a single literal. The literal is one found
+// in code or a random one. Parentheses are never replaced.
+func replaceRandom(i spogoto.Interpreter, code spogoto.Code, n int64, r spogoto.Rand) spogoto.Code {
+	items := []int{}
+	literals := spogoto.Code{}
+	for k, item := range code {
+		if item == "(" || item == ")" {
+			continue
+		}
+		items = append(items, k)
+		if parsed := i.Parse(spogoto.Code{item}); len(parsed) == 1 && parsed[0].Function == "" {
+			literals = append(literals, item)
+		}
+	}
+	if len(items) == 0 {
+		return code
+	}
+
+	start := items[r.Int63n(int64(len(items)))]
+	end := start
+	for end < len(code) && int64(end-start) < n && code[end] != "(" && code[end] != ")" {
+		end++
+	}
+
+	var literal string
+	if len(literals) > 0 && r.Float64() < 0.5 {
+		literal = literals[r.Int63n(int64(len(literals)))]
+	} else {
+		literal = i.WithRand(r).RandomLiteral([]string{"integer", "float", "boolean"}[r.Int63n(3)])
+	}
+
+	candidate := append(spogoto.Code{}, code[:start]...)
+	candidate = append(candidate, literal)
+	return append(candidate, code[end:]...)
+}
+
+// simpler returns true if a is shorter than b or as long with fewer
+// function Instructions.
+func simpler(i spogoto.Interpreter, a, b spogoto.Code) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return functions(i.Parse(a)) < functions(i.Parse(b))
+}
+
+// functions counts the function Instructions in s including those inside
+// blocks.
+func functions(s spogoto.InstructionSet) int {
+	count := 0
+	for _, in := range s {
+		if in.IsBlock() {
+			count += functions(in.Block)
+		} else if in.Function != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// equalErrors returns true if both error vectors are the same.
+func equalErrors(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if a[k] != b[k] {
+			return false
+		}
+	}
+	return true
+}
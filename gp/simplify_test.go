@@ -0,0 +1,83 @@
+package gp
+
+import (
+	"github.com/asartalo/spogoto"
+	. "github.com/smartystreets/goconvey/convey"
+	"math"
+	"testing"
+)
+
+func TestSimplify(t *testing.T) {
+	options := spogoto.DefaultOptions
+	options.Inputs = 1
+	i := spogoto.NewInterpreter(options)
+	cases := []Case{}
+	for _, n := range []int64{1, 2, 5, -3} {
+		cases = append(cases, Case{Inputs: spogoto.Inputs{n}, Expected: spogoto.Elements{n * 3}})
+	}
+	fitness := CaseFitness(cases, func(c Case, r spogoto.RunSet) float64 {
+		top, ok := r.TopInteger()
+		if !ok {
+			return 1000
+		}
+		return math.Abs(float64(top - c.Expected[0].(int64)))
+	})
+	r := spogoto.NewSplitMix64(1)
+
+	Convey("Given a program with dead instructions", t, func() {
+		code := spogoto.CodeFromString(
+			"true float.rand boolean.not ( in.0 in.0 ) 7 integer.pop in.0 float.pop integer.+ integer.+",
+		)
+
+		Convey("Simplifying keeps the errors and removes the dead instructions", func() {
+			simple := Simplify(i, fitness, code, 50, r)
+			So(fitness(i, simple), ShouldResemble, fitness(i, code))
+			So(simple, ShouldResemble, spogoto.CodeFromString("in.0 in.0 in.0 integer.+ integer.+"))
+		})
+
+		Convey("The program is not changed", func() {
+			Simplify(i, fitness, code, 10, r)
+			So(code[0], ShouldEqual, "true")
+		})
+	})
+
+	Convey("Given a program where nothing can be removed", t, func() {
+		code := spogoto.CodeFromString("in.0 in.0 in.0 integer.+ integer.+")
+
+		Convey("Simplifying returns the same program", func() {
+			So(Simplify(i, fitness, code, 20, r), ShouldResemble, code)
+		})
+	})
+
+	Convey("Blocks can be unwrapped", t, func() {
+		code := spogoto.CodeFromString("1 ( 2 ( 3 ) 4 ) 5")
+		unwrapped := map[string]bool{}
+		for k := 0; k < 20; k++ {
+			unwrapped[unwrapRandom(code, r).String()] = true
+		}
+		So(unwrapped, ShouldResemble, map[string]bool{
+			"1 2 (3) 4 5": true,
+			"1 (2 3 4) 5": true,
+		})
+		So(unwrapRandom(spogoto.CodeFromString("1 2"), r), ShouldResemble, spogoto.CodeFromString("1 2"))
+	})
+
+	Convey("Items can be replaced with a literal", t, func() {
+		code := spogoto.CodeFromString("1 ( integer.dup integer.+ ) integer.*")
+		for k := 0; k < 20; k++ {
+			replaced := replaceRandom(i, code, 1+r.Int63n(3), r)
+			So(len(replaced), ShouldBeLessThanOrEqualTo, len(code))
+			So(replaced, ShouldContain, "(")
+			So(replaced, ShouldContain, ")")
+			So(functions(i.Parse(replaced)), ShouldBeLessThanOrEqualTo, functions(i.Parse(code)))
+		}
+		So(replaceRandom(i, spogoto.CodeFromString("( )"), 2, r), ShouldResemble, spogoto.CodeFromString("( )"))
+	})
+
+	Convey("Shorter programs and programs with fewer functions are simpler", t, func() {
+		So(simpler(i, spogoto.CodeFromString("1 2"), spogoto.CodeFromString("1 2 3")), ShouldBeTrue)
+		So(simpler(i, spogoto.CodeFromString("1 2"), spogoto.CodeFromString("1 integer.dup")), ShouldBeTrue)
+		So(simpler(i, spogoto.CodeFromString("1 integer.dup"), spogoto.CodeFromString("1 2")), ShouldBeFalse)
+		So(simpler(i, spogoto.CodeFromString("1 2 3"), spogoto.CodeFromString("1 2")), ShouldBeFalse)
+	})
+}
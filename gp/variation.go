@@ -0,0 +1,92 @@
+package gp
+
+import (
+	"github.com/asartalo/spogoto"
+)
+
+// Operator creates a child program from parent programs.
+type Operator func(parents []spogoto.Code, i spogoto.Interpreter, r spogoto.Rand) spogoto.Code
+
+// Variation is an Operator that is applied to Parents selected parents
+// with a probability of Rate. Children that are not created by any
+// Variation are copies of a single parent.
+type Variation struct {
+	Operator Operator
+	Parents  int
+	Rate     float64
+}
+
+// UniformMutation returns an Operator that replaces each item of a parent
+// with a probability of rate.
+func UniformMutation(rate float64) Operator {
+	return func(parents []spogoto.Code, i spogoto.Interpreter, r spogoto.Rand) spogoto.Code {
+		return spogoto.UniformMutation(i, parents[0], rate, r)
+	}
+}
+
+// UniformInsertion returns an Operator that inserts a random Instruction
+// after each item of a parent with a probability of rate.
+func UniformInsertion(rate float64) Operator {
+	return func(parents []spogoto.Code, i spogoto.Interpreter, r spogoto.Rand) spogoto.Code {
+		return spogoto.UniformInsertion(i, parents[0], rate, r)
+	}
+}
+
+// UniformDeletion returns an Operator that removes each item of a parent
+// with a probability of rate.
+func UniformDeletion(rate float64) Operator {
+	return func(parents []spogoto.Code, i spogoto.Interpreter, r spogoto.Rand) spogoto.Code {
+		return spogoto.UniformDeletion(parents[0], rate, r)
+	}
+}
+
+// UniformCloseMutation returns an Operator that changes the block closing
+// after each item of a parent with a probability of rate.
+func UniformCloseMutation(rate float64) Operator {
+	return func(parents []spogoto.Code, i spogoto.Interpreter, r spogoto.Rand) spogoto.Code {
+		return spogoto.UniformCloseMutation(parents[0], rate, r)
+	}
+}
+
+// UniformCrossover returns an Operator that takes the item at each
+// position from the second parent with a probability of rate and from the
+// first parent otherwise.
+func UniformCrossover(rate float64) Operator {
+	return func(parents []spogoto.Code, i spogoto.Interpreter, r spogoto.Rand) spogoto.Code {
+		return spogoto.UniformCrossover(parents[0], parents[1], rate, r)
+	}
+}
+
+// OnePointCrossover returns an Operator that joins the start of the first
+// parent to the end of the second parent with a probability of rate.
+func OnePointCrossover(rate float64) Operator {
+	return func(parents []spogoto.Code, i spogoto.Interpreter, r spogoto.Rand) spogoto.Code {
+		return spogoto.OnePointCrossover(parents[0], parents[1], rate, r)
+	}
+}
+
+// TwoPointCrossover returns an Operator that replaces a segment of the
+// first parent with a segment of the second parent with a probability of
+// rate.
+func TwoPointCrossover(rate float64) Operator {
+	return func(parents []spogoto.Code, i spogoto.Interpreter, r spogoto.Rand) spogoto.Code {
+		return spogoto.TwoPointCrossover(parents[0], parents[1], rate, r)
+	}
+}
+
+// SizeFairCrossover returns an Operator that replaces a segment of the
+// first parent with a segment of the second parent that is no larger than
+// the replaced one with a probability of rate.
+func SizeFairCrossover(rate float64) Operator {
+	return func(parents []spogoto.Code, i spogoto.Interpreter, r spogoto.Rand) spogoto.Code {
+		return spogoto.SizeFairCrossover(i, parents[0], parents[1], rate, r)
+	}
+}
+
+// Alternation returns an Operator that alternates between two parents
+// with a probability of rate after each item.
+func Alternation(rate float64, deviation float64) Operator {
+	return func(parents []spogoto.Code, i spogoto.Interpreter, r spogoto.Rand) spogoto.Code {
+		return spogoto.Alternation(parents[0], parents[1], rate, deviation, r)
+	}
+}
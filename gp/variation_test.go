@@ -0,0 +1,36 @@
+package gp
+
+import (
+	"github.com/asartalo/spogoto"
+	. "github.com/smartystreets/goconvey/convey"
+	"math/rand"
+	"testing"
+)
+
+func TestVariations(t *testing.T) {
+	i := spogoto.NewInterpreter(spogoto.DefaultOptions)
+	a := spogoto.CodeFromString("1 2 3 4")
+	b := spogoto.CodeFromString("5 6 7")
+
+	Convey("Operators apply the variation with their rates", t, func() {
+		r := rand.New(rand.NewSource(1))
+		parents := []spogoto.Code{a, b}
+
+		So(UniformMutation(0)(parents, i, r), ShouldResemble, a)
+		So(UniformInsertion(0)(parents, i, r), ShouldResemble, a)
+		So(UniformDeletion(1)(parents, i, r), ShouldResemble, spogoto.Code{})
+		So(UniformCloseMutation(0)(parents, i, r), ShouldResemble, a)
+		So(Alternation(0, 0)(parents, i, r), ShouldBeIn, []spogoto.Code{a, b})
+		So(UniformCrossover(0)(parents, i, r), ShouldResemble, a)
+		So(TwoPointCrossover(0)(parents, i, r), ShouldResemble, a)
+	})
+
+	Convey("Crossover operators are deterministic under a Rand", t, func() {
+		for _, op := range []Operator{UniformCrossover(0.5), OnePointCrossover(1), TwoPointCrossover(1), SizeFairCrossover(1)} {
+			parents := []spogoto.Code{a, b}
+			child1 := op(parents, i, rand.New(rand.NewSource(3)))
+			child2 := op(parents, i, rand.New(rand.NewSource(3)))
+			So(child1, ShouldResemble, child2)
+		}
+	})
+}
@@ -26,6 +26,7 @@ func IntegerStackConstructor() (string, DataStack) {
 func addIntegerFunctions(ds *datastack) {
 	ds.FunctionMap["+"] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(2) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -35,6 +36,7 @@ func addIntegerFunctions(ds *datastack) {
 
 	ds.FunctionMap["*"] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(2) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -44,6 +46,7 @@ func addIntegerFunctions(ds *datastack) {
 
 	ds.FunctionMap["-"] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(2) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -51,7 +54,13 @@ func addIntegerFunctions(ds *datastack) {
 	}
 
 	ds.FunctionMap["/"] = func(d DataStack, r RunSet, i Interpreter) {
-		if d.Lack(2) || d.Peek().(int64) == 0 {
+		if d.Lack(2) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		if d.Peek().(int64) == 0 {
+			r.Noop(DivideByZero)
 			return
 		}
 
@@ -62,7 +71,13 @@ func addIntegerFunctions(ds *datastack) {
 	}
 
 	ds.FunctionMap["%"] = func(d DataStack, r RunSet, i Interpreter) {
-		if d.Lack(2) || d.Peek().(int64) == 0 {
+		if d.Lack(2) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		if d.Peek().(int64) == 0 {
+			r.Noop(DivideByZero)
 			return
 		}
 
@@ -74,6 +89,7 @@ func addIntegerFunctions(ds *datastack) {
 
 	ds.FunctionMap["min"] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(2) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -89,6 +105,7 @@ func addIntegerFunctions(ds *datastack) {
 
 	ds.FunctionMap["max"] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(2) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -104,6 +121,7 @@ func addIntegerFunctions(ds *datastack) {
 
 	ds.FunctionMap[">"] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(2) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -115,6 +133,7 @@ func addIntegerFunctions(ds *datastack) {
 
 	ds.FunctionMap["<"] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(2) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -126,6 +145,7 @@ func addIntegerFunctions(ds *datastack) {
 
 	ds.FunctionMap["="] = func(d DataStack, r RunSet, i Interpreter) {
 		if d.Lack(2) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -134,6 +154,7 @@ func addIntegerFunctions(ds *datastack) {
 
 	ds.FunctionMap["fromboolean"] = func(d DataStack, r RunSet, i Interpreter) {
 		if r.Bad("boolean", 1) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -147,6 +168,7 @@ func addIntegerFunctions(ds *datastack) {
 
 	ds.FunctionMap["fromfloat"] = func(d DataStack, r RunSet, i Interpreter) {
 		if r.Bad("float", 1) {
+			r.Noop(StackUnderflow)
 			return
 		}
 
@@ -154,6 +176,15 @@ func addIntegerFunctions(ds *datastack) {
 		d.Push(int64(f))
 	}
 
+	ds.FunctionMap["fromchar"] = func(d DataStack, r RunSet, i Interpreter) {
+		if r.Bad("char", 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		d.Push(int64(r.Stack("char").Pop().(rune)))
+	}
+
 	ds.FunctionMap["rand"] = func(d DataStack, r RunSet, i Interpreter) {
 		d.Push(i.RandInt())
 	}
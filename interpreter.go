@@ -4,27 +4,111 @@ package spogoto
 import (
 	"fmt"
 	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
 )
 
-// Interpreter interprets Spogoto code.
+// Interpreter interprets Spogoto code. An Interpreter can be used by many
+// goroutines at the same time if its Rand can. Use WithRand to give each
+// goroutine its own Rand.
 type Interpreter interface {
 	RandInt() int64
 	RandFloat() float64
 	RandomInstruction() string
+	RandomLiteral(string) string
 	RandomCode(int64) Code
 	Run(Code, StackState) RunSet
+	RunWithInputs(Code, StackState, Inputs) RunSet
+	Resume(RunSet) RunSet
+	Start(Code, StackState) *Execution
+	StartWithInputs(Code, StackState, Inputs) *Execution
+	Parse(Code) InstructionSet
 	StackConstructors() DataStackConstructors
+	WithRand(Rand) Interpreter
+	Config() Options
 }
 
 // Options sets the Instruction options changing its behavior depending
-// on what values are set on the fields.
+// on what values are set on the fields. Fields that hold functions or
+// interfaces are left out of JSON.
 type Options struct {
 
 	// MaxInstructions is the maximum number of total instruction executions
 	MaxInstructions int64
 
 	// Constructors for DataStacks to be used in the execution of code
-	StackConstructors DataStackConstructors
+	StackConstructors DataStackConstructors `json:"-"`
+
+	// Labels is the number of label Instructions available, from label.L0
+	// to label.L<Labels - 1>
+	Labels int64
+
+	// MaxCallDepth is the maximum number of nested cursor.call executions.
+	// Zero means there is no limit.
+	MaxCallDepth int64
+
+	// MaxPoints is the maximum number of points of Code built by code
+	// Instructions. Each item and each list is a point. Zero means the
+	// default of 100 and a negative value means there is no limit.
+	MaxPoints int64
+
+	// MaxStringLength is the maximum number of characters of strings built
+	// by string Instructions. Zero means the default of 5000 and a negative
+	// value means there is no limit.
+	MaxStringLength int64
+
+	// MaxVectorLength is the maximum number of items of vectors built by
+	// vector Instructions. Zero means the default of 5000 and a negative
+	// value means there is no limit.
+	MaxVectorLength int64
+
+	// Inputs is the number of input Instructions available, from in.0 to
+	// in.<Inputs - 1>
+	Inputs int64
+
+	// Outputs are the types of the output registers. An out.<type>
+	// Instruction is available for each one.
+	Outputs []string
+
+	// Seed seeds a SplitMix64 to generate random values. Zero means the
+	// global math/rand functions are used.
+	Seed int64
+
+	// Rand generates random values. It overrides Seed when set.
+	Rand Rand `json:"-"`
+
+	// LiteralChance is the probability of a random Instruction being a
+	// literal instead of a function. Zero means the default of 0.3 and a
+	// negative chance means there are no random literals.
+	LiteralChance float64
+
+	// IntegerRange is the lowest and highest random integer. When both are
+	// zero, random integers are from 0 to 9 and random integer literals
+	// have a random sign. An inverted range is treated as its reverse.
+	IntegerRange [2]int64
+
+	// FloatRange is the lowest random float and the limit of random floats.
+	// When both are zero, random floats are from 0 to 1 and random float
+	// literals have a random sign.
+	FloatRange [2]float64
+
+	// TypeWeights are the relative chances of random functions of each
+	// type. Types without a weight have a weight of 1. Negative weights
+	// count as zero.
+	TypeWeights map[string]float64
+
+	// InstructionWeights are the relative chances of random functions by
+	// symbol. They override the TypeWeights. NewInterpreter panics if the
+	// weights of all functions are zero.
+	InstructionWeights map[string]float64
+
+	// Constants generate random literals. When there are none, random
+	// literals are integers, floats and booleans.
+	Constants []Constant `json:"-"`
+
+	// Hooks are called before and after each Instruction of every run
+	Hooks []Hook `json:"-"`
 }
 
 // DefaultOptions is the default set of options.
@@ -32,7 +116,70 @@ var DefaultOptions = Options{
 	MaxInstructions: 100,
 	StackConstructors: []DataStackConstructor{
 		IntegerStackConstructor, FloatStackConstructor, BooleanStackConstructor,
+		ExecStackConstructor, CodeStackConstructor, StringStackConstructor,
+		VectorIntegerStackConstructor, VectorFloatStackConstructor,
+		VectorBooleanStackConstructor, CharStackConstructor,
+		NameStackConstructor,
 	},
+	Labels:        10,
+	MaxCallDepth:  32,
+	LiteralChance: defaultLiteralChance,
+}
+
+const defaultLiteralChance = 0.3
+
+const defaultMaxPoints = 100
+
+const defaultMaxStringLength = 5000
+
+const defaultMaxVectorLength = 5000
+
+// withinLimit checks if size is at most limit. A zero limit means the
+// default limit and a negative limit means there is no limit.
+func withinLimit(size, limit, defaultLimit int64) bool {
+	if limit == 0 {
+		limit = defaultLimit
+	}
+	return limit < 0 || size <= limit
+}
+
+// Constant generates a random literal. Constants are also known as
+// ephemeral random constants.
+type Constant func(r Rand) string
+
+// IntegerConstant returns a Constant that generates integers from min to
+// max.
+func IntegerConstant(min int64, max int64) Constant {
+	return func(r Rand) string {
+		return strconv.FormatInt(randRange(r, min, max), 10)
+	}
+}
+
+// randRange returns a random integer from min to max. The bounds are
+// swapped if they are inverted. Ranges wider than Int63n allows are drawn
+// 64 bits at a time until a value falls within them.
+func randRange(r Rand, min int64, max int64) int64 {
+	if min > max {
+		min, max = max, min
+	}
+	if span := max - min + 1; span > 0 {
+		return min + r.Int63n(span)
+	}
+	for {
+		n := int64(uint64(r.Int63n(1<<32))<<32 | uint64(r.Int63n(1<<32)))
+		if n >= min && n <= max {
+			return n
+		}
+	}
+}
+
+// FloatConstant returns a Constant that generates floats from min up to
+// but not including max.
+func FloatConstant(min float64, max float64) Constant {
+	return func(r Rand) string {
+		str, _ := formatFloat(min + r.Float64()*(max-min))
+		return str
+	}
 }
 
 type Rand interface {
@@ -52,53 +199,192 @@ func (r rander) Float64() float64 {
 
 type StackState map[string]Elements
 
+// Inputs are the input values of a run. Each in.N Instruction pushes a
+// copy of the Nth input to the stack of its type without consuming it.
+type Inputs Elements
+
 type interpreter struct {
 	Rand    Rand
 	Parser  *Parser
 	Options Options
+
+	// symbolWeights are the weights of the Parser symbols when there are
+	// TypeWeights or InstructionWeights
+	symbolWeights []float64
+	totalWeight   float64
 }
 
 // Run executes a Spogoto code string and returns a RunSet as result.
-func (i *interpreter) Run(code Code, stackState StackState) (r RunSet) {
-	r = i.createRunSet(stackState)
-	instructions := i.Parser.Parse(code)
-	inCount := int64(len(instructions))
-	r.Cursor().Instructions = instructions
-	for r.Cursor().Position < inCount {
-		instruction := instructions[r.Cursor().Position]
-		t := instruction.Type
-		fn := instruction.Function
-		if t == "" {
-			// No type so noop
-			r.Cursor().Position++
-			continue
+// The RunSet records why the execution stopped and the Instructions that
+// did nothing. Instructions pending on the exec stack are executed before
+// the Cursor moves to the next Instruction.
+func (i *interpreter) Run(code Code, stackState StackState) RunSet {
+	return i.RunWithInputs(code, stackState, nil)
+}
+
+// RunWithInputs executes code like Run with inputs available to the in.N
+// Instructions.
+func (i *interpreter) RunWithInputs(code Code, stackState StackState, inputs Inputs) RunSet {
+	return i.Resume(i.StartWithInputs(code, stackState, inputs).RunSet())
+}
+
+// Resume continues running a RunSet that has not halted, such as a fork
+// of a RunSet that was captured during a run. The RunSet must have been
+// created by an Interpreter.
+func (i *interpreter) Resume(rs RunSet) RunSet {
+	r := rs.(*runset)
+	for i.step(r, i.Options.Hooks) {
+	}
+	return r
+}
+
+// Start prepares code to be run step by step on a stack state. The
+// Execution is already halted if there is nothing to run.
+func (i *interpreter) Start(code Code, stackState StackState) *Execution {
+	return i.StartWithInputs(code, stackState, nil)
+}
+
+// StartWithInputs prepares code like Start with inputs available to the
+// in.N Instructions.
+func (i *interpreter) StartWithInputs(code Code, stackState StackState, inputs Inputs) *Execution {
+	r := i.createRunSet(stackState)
+	r.inputs = inputs
+	i.recordUnknown(r, code)
+	r.Cursor().Instructions = i.Parser.Parse(code)
+	if _, _, ok := i.next(r); !ok {
+		r.Halt(HaltCompleted)
+	}
+	return newExecution(i, r)
+}
+
+// next returns the Instruction that will be executed next and its Cursor
+// position or -1 if it is on the exec stack. It returns false if there
+// is none.
+func (i *interpreter) next(r *runset) (Instruction, int64, bool) {
+	if r.HaltReason() != NotHalted {
+		return Instruction{}, 0, false
+	}
+	if r.Ok("exec", 1) {
+		return r.Stack("exec").Peek().(Instruction), -1, true
+	}
+	if pos := r.Cursor().Position; pos < instructionCount(r) {
+		return r.Cursor().Instructions[pos], pos, true
+	}
+	return Instruction{}, 0, false
+}
+
+// step executes the next Instruction calling the hooks before and after
+// it. The run halts as soon as the Instruction leaves nothing to execute
+// or reaches the instruction limit, before the After hooks are called. It
+// returns false if the run has halted and nothing was executed.
+func (i *interpreter) step(r *runset, hooks []Hook) bool {
+	in, idx, ok := i.next(r)
+	if !ok {
+		if r.HaltReason() == NotHalted {
+			r.Halt(HaltCompleted)
 		}
+		return false
+	}
 
-		if fn == "" {
-			// Literal type
-			r.Stack(t).PushLiteral(instruction.Value)
-		} else if t == "cursor" {
-			r.CursorCommand(fn)
+	if idx < 0 {
+		// The Cursor stays on the Instruction before the next one so that
+		// cursor commands behave the same way as with Cursor Instructions.
+		r.Stack("exec").Pop()
+		r.Cursor().Position--
+	}
+	r.current = in
+	r.currentIndex = idx
+
+	for _, h := range hooks {
+		h.Before(in, r)
+	}
+	i.execute(r, in)
+	r.Cursor().Position++
+	r.IncrementInstructionCount()
+	if r.InstructionCount() > i.Options.MaxInstructions {
+		r.Halt(HaltMaxInstructions)
+	} else if _, _, ok := i.next(r); !ok && r.HaltReason() == NotHalted {
+		r.Halt(HaltCompleted)
+	}
+	for _, h := range hooks {
+		h.After(in, r)
+	}
+
+	return true
+}
+
+// execute executes a single Instruction.
+func (i *interpreter) execute(r RunSet, instruction Instruction) {
+	t := instruction.Type
+	fn := instruction.Function
+	if instruction.IsBlock() {
+		pushExec(r.Stack("exec"), instruction.Block...)
+	} else if t == "name" && fn == "" {
+		// Bound names execute their binding, other names are literals
+		if binding, ok := r.Binding(instruction.Value); ok {
+			pushExec(r.Stack("exec"), binding)
 		} else {
-			// It's calling a function
-			r.Stack(t).Call(fn, r, i)
+			r.Stack(t).PushLiteral(instruction.Value)
 		}
+	} else if fn == "" {
+		// Literal type
+		r.Stack(t).PushLiteral(instruction.Value)
+	} else if t == "label" {
+		// Labels only mark positions for cursor commands
+	} else if t == "cursor" {
+		r.CursorCommand(fn)
+	} else if t == "in" {
+		pushInput(r, fn)
+	} else if t == "out" {
+		if r.Bad(fn, 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+		r.SetOutput(fn, r.Stack(fn).Peek())
+	} else {
+		// It's calling a function
+		r.Stack(t).Call(fn, r, i)
+	}
+}
 
-		r.Cursor().Position++
-		r.IncrementInstructionCount()
-		if r.InstructionCount() > i.Options.MaxInstructions {
-			break
+// recordUnknown records the items in code that the Parser drops.
+func (i *interpreter) recordUnknown(r *runset, code Code) {
+	for idx, item := range code {
+		if item == "(" || item == ")" {
+			continue
+		}
+		if i.Parser.ParseItem(item).Type == "" {
+			r.errors = append(r.errors, RunError{int64(idx), item, UnknownInstruction})
 		}
 	}
+}
 
-	return r
+// Parse parses code into an InstructionSet using the Interpreter's Parser.
+func (i *interpreter) Parse(code Code) InstructionSet {
+	return i.Parser.Parse(code)
 }
 
 func (i *interpreter) StackConstructors() DataStackConstructors {
 	return i.Options.StackConstructors
 }
 
-// RandomCodeArray generates a random code of the specified length.
+// Config returns the Options of the Interpreter.
+func (i *interpreter) Config() Options {
+	return i.Options
+}
+
+// WithRand returns a copy of the Interpreter that generates random values
+// with r. The copy shares the Parser and Options of the Interpreter.
+func (i *interpreter) WithRand(r Rand) Interpreter {
+	c := *i
+	c.Rand = r
+	return &c
+}
+
+// RandomCode generates a random code of the specified length. Random
+// Instructions are never blocks so the size of the parsed code is its
+// length and the length is the only size limit needed here. Sizes of
+// evolved code are limited by gp.Options.MaxSize.
 func (i *interpreter) RandomCode(length int64) Code {
 	var code = Code{}
 	var k int64
@@ -109,20 +395,37 @@ func (i *interpreter) RandomCode(length int64) Code {
 	return code
 }
 
-// RandInt generates a random integer between 0 and 9.
+// RandInt generates a random integer within Options.IntegerRange or
+// between 0 and 9 if there is no range.
 func (i *interpreter) RandInt() int64 {
-	return i.Rand.Int63n(10)
+	min, max := i.Options.IntegerRange[0], i.Options.IntegerRange[1]
+	if min == 0 && max == 0 {
+		return i.Rand.Int63n(10)
+	}
+	return randRange(i.Rand, min, max)
 }
 
-// RandFloat generates a random float number between 0 and 1.
+// RandFloat generates a random float number within Options.FloatRange or
+// between 0 and 1 if there is no range.
 func (i *interpreter) RandFloat() float64 {
-	return i.Rand.Float64()
+	min, max := i.Options.FloatRange[0], i.Options.FloatRange[1]
+	if min == 0 && max == 0 {
+		return i.Rand.Float64()
+	}
+	return min + i.Rand.Float64()*(max-min)
 }
 
 // RandomInstruction generates a random instruction. An Instruction can either be
 // a literal or a function.
 func (i *interpreter) RandomInstruction() string {
-	if i.RandFloat() < 0.3 {
+	chance := i.Options.LiteralChance
+	if chance == 0 {
+		chance = defaultLiteralChance
+	}
+	if i.Rand.Float64() < chance {
+		if constants := i.Options.Constants; len(constants) > 0 {
+			return constants[i.Rand.Int63n(int64(len(constants)))](i.Rand)
+		}
 		return i.RandomLiteral([]string{"integer", "float", "boolean"}[i.Rand.Int63n(3)])
 	}
 	return i.RandomSymbol()
@@ -140,64 +443,172 @@ func (i *interpreter) RandomLiteral(t string) string {
 	}
 }
 
-// RandomSymbol generates a random DataSet or Cursor function.
+// RandomSymbol generates a random DataSet or Cursor function. Functions
+// are chosen according to Options.TypeWeights and
+// Options.InstructionWeights.
 func (i *interpreter) RandomSymbol() string {
 	symbols := i.Parser.Symbols()
-	idx := i.Rand.Int63n(int64(len(symbols)))
-	return symbols[idx]
+	if i.symbolWeights == nil {
+		return symbols[i.Rand.Int63n(int64(len(symbols)))]
+	}
+
+	chance := i.Rand.Float64() * i.totalWeight
+	last := 0
+	for k, w := range i.symbolWeights {
+		if w <= 0 {
+			continue
+		}
+		if chance < w {
+			return symbols[k]
+		}
+		chance -= w
+		last = k
+	}
+	// Rounding can leave a little chance after the last weight
+	return symbols[last]
+}
+
+// setupWeights computes the weights of the Parser symbols once so that
+// RandomSymbol doesn't have to.
+func (i *interpreter) setupWeights() {
+	if i.Options.TypeWeights == nil && i.Options.InstructionWeights == nil {
+		return
+	}
+
+	symbols := i.Parser.Symbols()
+	weights := make([]float64, len(symbols))
+	total := 0.0
+	for k, symbol := range symbols {
+		weights[k] = i.symbolWeight(symbol)
+		total += weights[k]
+	}
+	if total == 0 {
+		panic("spogoto: the weights of all instructions are zero")
+	}
+
+	i.symbolWeights = weights
+	i.totalWeight = total
+}
+
+// symbolWeight returns the relative chance of a symbol being chosen.
+func (i *interpreter) symbolWeight(symbol string) float64 {
+	w, ok := i.Options.InstructionWeights[symbol]
+	if !ok {
+		t := strings.SplitN(symbol, ".", 2)[0]
+		if w, ok = i.Options.TypeWeights[t]; !ok {
+			w = 1
+		}
+	}
+	if !(w > 0) {
+		return 0
+	}
+	return w
 }
 
 func (i *interpreter) randomBoolean() string {
-	if i.RandFloat() > 0.5 {
+	if i.Rand.Float64() > 0.5 {
 		return "true"
 	}
 	return "false"
 }
 
 func (i *interpreter) randomInteger() string {
+	if i.Options.IntegerRange != [2]int64{} {
+		return strconv.FormatInt(i.RandInt(), 10)
+	}
+
 	var sign = ""
-	if i.RandFloat() > 0.5 {
+	if i.Rand.Float64() > 0.5 {
 		sign = "-"
 	}
 	return fmt.Sprintf("%s%d", sign, i.RandInt())
 }
 
 func (i *interpreter) randomFloat() string {
+	if i.Options.FloatRange != [2]float64{} {
+		str, _ := formatFloat(i.RandFloat())
+		return str
+	}
+
 	var sign = ""
-	if i.RandFloat() > 0.5 {
+	if i.Rand.Float64() > 0.5 {
 		sign = "-"
 	}
 	return fmt.Sprintf("%s%f", sign, i.RandFloat())
 }
 
+// setupParser registers the Instructions of the Interpreter. They are
+// registered in a sorted order so that random Instructions are the same
+// for the same Rand.
 func (i *interpreter) setupParser(r RunSet) {
 	p := NewParser()
-	for t, stack := range r.DataStacks() {
-		for fn := range stack.Functions() {
+	dataStacks := r.DataStacks()
+	types := []string{}
+	for t := range dataStacks {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		for _, fn := range sortedKeys(dataStacks[t].Functions()) {
 			p.RegisterFunction(t, fn)
 		}
 	}
 
+	commands := []string{}
 	for fnc := range r.CursorCommands() {
+		commands = append(commands, fnc)
+	}
+	sort.Strings(commands)
+	for _, fnc := range commands {
 		p.RegisterFunction("cursor", fnc)
 	}
 
+	var k int64
+	for k = 0; k < i.Options.Labels; k++ {
+		p.RegisterFunction("label", labelName(k))
+	}
+
+	for k = 0; k < i.Options.Inputs; k++ {
+		p.RegisterFunction("in", strconv.FormatInt(k, 10))
+	}
+
+	for _, t := range i.Options.Outputs {
+		p.RegisterFunction("out", t)
+	}
+
 	i.Parser = p
 }
 
+// sortedKeys returns the function names of a FunctionMap in order.
+func sortedKeys(functions FunctionMap) []string {
+	keys := []string{}
+	for fn := range functions {
+		keys = append(keys, fn)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // NewInterpreter constructs a new Intepreter configured with options.
 func NewInterpreter(options Options) *interpreter {
 	i := &interpreter{
 		Rand:    rander(1),
 		Options: options,
 	}
+	if options.Rand != nil {
+		i.Rand = options.Rand
+	} else if options.Seed != 0 {
+		i.Rand = NewSplitMix64(options.Seed)
+	}
 	i.setupParser(i.createRunSet(StackState{}))
+	i.setupWeights()
 
 	return i
 }
 
 func (i *interpreter) createRunSet(stackState StackState) *runset {
 	r := NewRunSet(i)
+	r.maxCallDepth = i.Options.MaxCallDepth
 
 	for stackType, elements := range stackState {
 		r.InitializeStack(stackType, elements)
@@ -3,7 +3,10 @@ package spogoto
 import (
 	"fmt"
 	. "github.com/smartystreets/goconvey/convey"
+	"math"
 	"regexp"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -235,6 +238,150 @@ func TestCursorFunctions(t *testing.T) {
 			[]bool{}, []bool{},
 			[]float64{}, []float64{},
 		},
+		{
+			"Gotolabel with a matching label",
+			"1 cursor.gotolabel 2 label.L1 8",
+			"Will go to the label",
+			[]int64{}, []int64{8},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Gotolabel after code was inserted before the label",
+			"1 cursor.gotolabel 2 3 4 label.L1 8",
+			"Will still go to the label",
+			[]int64{}, []int64{8},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Gotolabel with no matching label",
+			"2 cursor.gotolabel 3 label.L1 8",
+			"Will do nothing",
+			[]int64{}, []int64{3, 8},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Gotolabel on code without labels",
+			"3 cursor.gotolabel 2 1 8",
+			"Will go to the position like goto",
+			[]int64{}, []int64{1, 8},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Gotolabelif with boolean true",
+			"true 1 cursor.gotolabelif 2 label.L1 8",
+			"Will go to the label",
+			[]int64{}, []int64{8},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Gotolabelif with boolean false",
+			"false 1 cursor.gotolabelif 2 label.L1 8",
+			"Will not go to the label",
+			[]int64{}, []int64{1, 2, 8},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Jump with positive offset",
+			"2 cursor.jump 3 4 5",
+			"Will skip the number of instructions",
+			[]int64{}, []int64{5},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Jump with negative offset",
+			"2 cursor.jump 7 cursor.end 8 -5 cursor.jump",
+			"Will jump backwards",
+			[]int64{}, []int64{8, 7},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Jumpif with boolean false",
+			"false 2 cursor.jumpif 3 4 5",
+			"Will not jump",
+			[]int64{}, []int64{2, 3, 4, 5},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Jump outside of the code",
+			"9 cursor.jump 3",
+			"Will do nothing",
+			[]int64{}, []int64{3},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Loop",
+			"3 cursor.loop 1 cursor.next 2",
+			"Will execute the loop body a number of times",
+			[]int64{}, []int64{1, 1, 1, 2},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Nested loops with loop index",
+			"2 cursor.loop 2 cursor.loop cursor.loopindex cursor.next cursor.next",
+			"Will execute inner loops for each outer iteration",
+			[]int64{}, []int64{0, 1, 0, 1},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Loop with zero count",
+			"0 cursor.loop 1 2 cursor.loop 3 cursor.next cursor.next 4",
+			"Will skip the loop body",
+			[]int64{}, []int64{4},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Next without loop",
+			"1 cursor.next 2",
+			"Will do nothing",
+			[]int64{}, []int64{1, 2},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Loop inside a block",
+			"(3 cursor.loop cursor.loopindex cursor.next) 7",
+			"Will do nothing",
+			[]int64{}, []int64{3, 7},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Call and return",
+			"5 cursor.call 1 cursor.end label.L5 2 cursor.return",
+			"Will run the subroutine and come back",
+			[]int64{}, []int64{2, 1},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Call without labels",
+			"5 cursor.call 1 cursor.end 2 3 cursor.return",
+			"Will call the position",
+			[]int64{}, []int64{3, 1},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
+		{
+			"Return without call",
+			"1 cursor.return 2",
+			"Will do nothing",
+			[]int64{}, []int64{1, 2},
+			[]bool{}, []bool{},
+			[]float64{}, []float64{},
+		},
 		{
 			"Gotoif with nothing on boolean stack",
 			"3.0 4 cursor.gotoif 2 1 8",
@@ -259,3 +406,407 @@ func TestCursorFunctions(t *testing.T) {
 		})
 	}
 }
+
+func TestRunResult(t *testing.T) {
+	testData := []struct {
+		code   string
+		reason HaltReason
+		errors []RunError
+	}{
+		{"1 2 integer.+", HaltCompleted, nil},
+		{"1 cursor.end 2", HaltEnd, nil},
+		{"true cursor.endif 2", HaltEnd, nil},
+		{"0 cursor.goto", HaltMaxInstructions, nil},
+		{"1 foo.bar 2", HaltCompleted, []RunError{{1, "foo.bar", UnknownInstruction}}},
+		{"1 integer.+", HaltCompleted, []RunError{{1, "integer.+", StackUnderflow}}},
+		{"1 0 integer./", HaltCompleted, []RunError{{2, "integer./", DivideByZero}}},
+		{"1.0 0.0 float.%", HaltCompleted, []RunError{{2, "float.%", DivideByZero}}},
+		{"10 cursor.goto", HaltCompleted, []RunError{{1, "cursor.goto", InvalidJump}}},
+		{"cursor.skipif", HaltCompleted, []RunError{{0, "cursor.skipif", StackUnderflow}}},
+		{"(3 cursor.loop cursor.loopindex cursor.next) 7", HaltCompleted, []RunError{
+			{-1, "cursor.loop", LoopInBlock},
+			{-1, "cursor.loopindex", StackUnderflow},
+			{-1, "cursor.next", LoopInBlock},
+		}},
+	}
+
+	for _, d := range testData {
+		Convey(fmt.Sprintf("Running code `%s`", d.code), t, func() {
+			i := NewInterpreter(DefaultOptions)
+			r := i.Run(CodeFromString(d.code), StackState{})
+
+			Convey(fmt.Sprintf("Should halt with reason '%s'", d.reason), func() {
+				So(r.HaltReason(), ShouldEqual, d.reason)
+			})
+
+			Convey(fmt.Sprintf("Should record errors %v", d.errors), func() {
+				So(r.Errors(), ShouldResemble, d.errors)
+			})
+
+			Convey("Should not leave loops behind", func() {
+				So(r.Loops().IsEmpty(), ShouldBeTrue)
+			})
+		})
+	}
+}
+
+func TestCallDepth(t *testing.T) {
+	Convey("Given code that calls itself", t, func() {
+		options := DefaultOptions
+		options.MaxCallDepth = 3
+		i := NewInterpreter(options)
+		r := i.Run(CodeFromString("label.L0 0 cursor.call"), StackState{})
+
+		Convey("Calls stop at the maximum depth", func() {
+			So(r.Returns().Size(), ShouldEqual, 3)
+			So(r.Errors()[0], ShouldResemble, RunError{2, "cursor.call", CallDepthExceeded})
+		})
+		Convey("A fork of a run keeps the maximum depth", func() {
+			e := i.Start(CodeFromString("label.L0 0 cursor.call"), StackState{})
+			e.Step()
+			f := i.Resume(e.RunSet().Fork())
+			So(f.MaxCallDepth(), ShouldEqual, 3)
+			So(f.Returns().Size(), ShouldEqual, 3)
+		})
+	})
+
+	Convey("Given code that returns without calling", t, func() {
+		i := NewInterpreter(DefaultOptions)
+		r := i.Run(CodeFromString("cursor.return"), StackState{})
+
+		Convey("The mismatch is recorded", func() {
+			So(r.Errors(), ShouldResemble, []RunError{{0, "cursor.return", UnmatchedReturn}})
+		})
+	})
+}
+
+func TestInputs(t *testing.T) {
+	Convey("Given an Interpreter with input instructions", t, func() {
+		options := DefaultOptions
+		options.Inputs = 3
+		i := NewInterpreter(options)
+
+		Convey("Input instructions are available as symbols", func() {
+			So(i.Parser.Symbols(), ShouldContain, "in.2")
+			So(i.Parser.FunctionRegistered("in", "3"), ShouldBeFalse)
+		})
+
+		Convey("When running code that uses inputs several times", func() {
+			inputs := Inputs{int64(3), 1.5, []int64{1, 2}}
+			r := i.RunWithInputs(
+				CodeFromString("in.0 in.0 integer.* in.1 in.2 in.2 vector_integer.pop in.0"),
+				StackState{}, inputs,
+			)
+
+			Convey("Each instruction pushes a copy of the input", func() {
+				So(r.Stack("integer").Elements(), ShouldResemble, int64Elements([]int64{9, 3}))
+				So(r.Stack("float").Elements(), ShouldResemble, float64Elements([]float64{1.5}))
+				So(r.Stack("vector_integer").Elements(), ShouldResemble, Elements{[]int64{1, 2}})
+			})
+
+			Convey("The inputs are not consumed", func() {
+				So(r.Inputs(), ShouldResemble, inputs)
+			})
+		})
+
+		Convey("When running code with missing inputs", func() {
+			r := i.RunWithInputs(CodeFromString("in.2"), StackState{}, Inputs{int64(1)})
+
+			Convey("The missing input is recorded", func() {
+				So(r.Errors(), ShouldResemble, []RunError{{0, "in.2", MissingInput}})
+			})
+		})
+	})
+}
+
+func TestOutputs(t *testing.T) {
+	Convey("Given an Interpreter with output registers", t, func() {
+		options := DefaultOptions
+		options.Outputs = []string{"integer", "float"}
+		i := NewInterpreter(options)
+
+		Convey("Output instructions are available as symbols", func() {
+			So(i.Parser.Symbols(), ShouldContain, "out.integer")
+			So(i.Parser.FunctionRegistered("out", "boolean"), ShouldBeFalse)
+		})
+
+		Convey("When running code that writes outputs", func() {
+			r := i.Run(CodeFromString("3 out.integer 4 out.integer integer.pop out.float"), StackState{})
+
+			Convey("The last written value is the output", func() {
+				value, ok := r.Output("integer")
+				So(ok, ShouldBeTrue)
+				So(value, ShouldEqual, 4)
+			})
+
+			Convey("Registers that were not written have no output", func() {
+				_, ok := r.Output("float")
+				So(ok, ShouldBeFalse)
+				So(r.Errors(), ShouldResemble, []RunError{{5, "out.float", StackUnderflow}})
+			})
+		})
+	})
+
+	Convey("Given the result of a run", t, func() {
+		i := NewInterpreter(DefaultOptions)
+		r := i.Run(CodeFromString("1 2 true"), StackState{})
+
+		Convey("The top values of stacks are available", func() {
+			integer, ok := r.TopInteger()
+			So(ok, ShouldBeTrue)
+			So(integer, ShouldEqual, 2)
+
+			b, ok := r.TopBool()
+			So(ok, ShouldBeTrue)
+			So(b, ShouldBeTrue)
+		})
+
+		Convey("Empty stacks have no top value", func() {
+			_, ok := r.TopFloat()
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
+
+func TestInterpreterRand(t *testing.T) {
+	Convey("Given Interpreters with the same seed", t, func() {
+		options := DefaultOptions
+		options.Seed = 99
+		i1 := NewInterpreter(options)
+		i2 := NewInterpreter(options)
+
+		Convey("They generate the same random code", func() {
+			So(i1.RandomCode(50), ShouldResemble, i2.RandomCode(50))
+		})
+
+		Convey("Random instructions produce the same results", func() {
+			code := CodeFromString("integer.rand float.rand")
+			r1 := i1.Run(code, StackState{})
+			r2 := i2.Run(code, StackState{})
+			So(r1.Stack("integer").Elements(), ShouldResemble, r2.Stack("integer").Elements())
+			So(r1.Stack("float").Elements(), ShouldResemble, r2.Stack("float").Elements())
+		})
+
+		Convey("Their symbols are in the same order", func() {
+			So(i1.Parser.Symbols(), ShouldResemble, i2.Parser.Symbols())
+			So(i1.Parser.Symbols()[0], ShouldEqual, "boolean.=")
+		})
+	})
+
+	Convey("Given an Interpreter with a custom Rand", t, func() {
+		options := DefaultOptions
+		options.Seed = 99
+		options.Rand = NewSplitMix64(5)
+		i := NewInterpreter(options)
+
+		Convey("The Rand overrides the seed", func() {
+			So(i.RandFloat(), ShouldEqual, NewSplitMix64(5).Float64())
+		})
+	})
+}
+
+func TestRandomDistributions(t *testing.T) {
+	Convey("Given an Interpreter without literals", t, func() {
+		options := DefaultOptions
+		options.Seed = 3
+		options.LiteralChance = -1
+		i := NewInterpreter(options)
+
+		Convey("Random Instructions are functions", func() {
+			for _, item := range i.RandomCode(100) {
+				So(item, ShouldContainSubstring, ".")
+				So(i.Parser.ParseItem(item).Function, ShouldNotEqual, "")
+			}
+		})
+	})
+
+	Convey("Given an Interpreter with ranges", t, func() {
+		options := DefaultOptions
+		options.Seed = 3
+		options.LiteralChance = 1
+		options.IntegerRange = [2]int64{-1000, 1000}
+		options.FloatRange = [2]float64{100, 200}
+		i := NewInterpreter(options)
+
+		Convey("Random values are within the ranges", func() {
+			wide := false
+			for k := 0; k < 100; k++ {
+				n := i.RandInt()
+				So(n, ShouldBeBetweenOrEqual, -1000, 1000)
+				wide = wide || n > 9 || n < -9
+				f := i.RandFloat()
+				So(f, ShouldBeGreaterThanOrEqualTo, 100)
+				So(f, ShouldBeLessThan, 200)
+			}
+			So(wide, ShouldBeTrue)
+		})
+
+		Convey("Random literals are within the ranges", func() {
+			for k := 0; k < 20; k++ {
+				n, _ := strconv.ParseInt(i.RandomLiteral("integer"), 10, 64)
+				So(n, ShouldBeBetweenOrEqual, -1000, 1000)
+				f, _ := strconv.ParseFloat(i.RandomLiteral("float"), 64)
+				So(f, ShouldBeBetweenOrEqual, 100, 200)
+			}
+		})
+	})
+
+	Convey("Given options without a literal chance", t, func() {
+		i := NewInterpreter(Options{
+			StackConstructors: DefaultOptions.StackConstructors,
+			Seed:              3,
+		})
+
+		Convey("Random Instructions include literals", func() {
+			literals := 0
+			for _, item := range i.RandomCode(1000) {
+				if i.Parser.ParseItem(item).Function == "" {
+					literals++
+				}
+			}
+			So(literals, ShouldBeBetween, 200, 400)
+		})
+	})
+
+	Convey("Given inverted and very wide ranges", t, func() {
+		options := DefaultOptions
+		options.Seed = 3
+		options.IntegerRange = [2]int64{5, 1}
+		inverted := NewInterpreter(options)
+		options.IntegerRange = [2]int64{math.MinInt64, math.MaxInt64}
+		wide := NewInterpreter(options)
+
+		Convey("Random integers don't panic and stay within the ranges", func() {
+			negative := false
+			for k := 0; k < 100; k++ {
+				So(inverted.RandInt(), ShouldBeBetweenOrEqual, 1, 5)
+				negative = negative || wide.RandInt() < 0
+			}
+			So(negative, ShouldBeTrue)
+			constant := IntegerConstant(10, -10)
+			n, _ := strconv.ParseInt(constant(NewSplitMix64(1)), 10, 64)
+			So(n, ShouldBeBetweenOrEqual, -10, 10)
+		})
+	})
+
+	Convey("Given an Interpreter with constants", t, func() {
+		options := DefaultOptions
+		options.Seed = 3
+		options.LiteralChance = 1
+		options.Constants = []Constant{IntegerConstant(500, 510), FloatConstant(-2, -1)}
+		i := NewInterpreter(options)
+
+		Convey("Random literals come from the constants", func() {
+			for _, item := range i.RandomCode(50) {
+				in := i.Parser.ParseItem(item)
+				switch in.Type {
+				case "integer":
+					n, _ := strconv.ParseInt(item, 10, 64)
+					So(n, ShouldBeBetweenOrEqual, 500, 510)
+				case "float":
+					f, _ := strconv.ParseFloat(item, 64)
+					So(f, ShouldBeBetweenOrEqual, -2, -1)
+				default:
+					So(in.Type, ShouldBeIn, []string{"integer", "float"})
+				}
+			}
+		})
+	})
+
+	Convey("Given an Interpreter with weights", t, func() {
+		options := DefaultOptions
+		options.Seed = 3
+		options.TypeWeights = map[string]float64{"integer": 1}
+		options.InstructionWeights = map[string]float64{"float.+": 1}
+		for _, constructor := range options.StackConstructors {
+			t, _ := constructor()
+			if t != "integer" {
+				options.TypeWeights[t] = 0
+			}
+		}
+		options.TypeWeights["cursor"] = 0
+		options.TypeWeights["label"] = 0
+		i := NewInterpreter(options)
+
+		Convey("Random symbols follow the weights", func() {
+			seen := map[string]bool{}
+			for k := 0; k < 500; k++ {
+				symbol := i.RandomSymbol()
+				seen[symbol] = true
+				if !strings.HasPrefix(symbol, "integer.") {
+					So(symbol, ShouldEqual, "float.+")
+				}
+			}
+			So(seen["float.+"], ShouldBeTrue)
+			So(seen["integer.+"], ShouldBeTrue)
+		})
+
+		Convey("Weights that are all zero are rejected", func() {
+			options.TypeWeights["integer"] = 0
+			options.InstructionWeights = nil
+			So(func() { NewInterpreter(options) }, ShouldPanic)
+		})
+	})
+
+	Convey("Given default options", t, func() {
+		options := DefaultOptions
+		options.Seed = 3
+		i := NewInterpreter(options)
+
+		Convey("Random literals keep their default form", func() {
+			for k := 0; k < 20; k++ {
+				So(i.RandomLiteral("integer"), ShouldMatch, `^-?\d$`)
+				So(i.RandomLiteral("float"), ShouldMatch, `^-?0\.\d{6}$`)
+			}
+		})
+	})
+}
+
+func TestFork(t *testing.T) {
+	Convey("Given a RunSet captured in the middle of a run", t, func() {
+		i := NewInterpreter(DefaultOptions)
+		code := CodeFromString("1 2 integer.+ 3 integer.shove 2 cursor.loop 10 integer.* cursor.next 4 integer.yank")
+		state := StackState{"integer": Elements{int64(7), int64(8)}}
+
+		// Run the first three Instructions from the Cursor
+		half := i.createRunSet(state)
+		half.Cursor().Instructions = i.Parse(code)
+		for half.Cursor().Position < 3 {
+			i.execute(half, half.Cursor().Instructions[half.Cursor().Position])
+			half.Cursor().Position++
+			half.IncrementInstructionCount()
+		}
+		snapshot := half.Snapshot()
+
+		Convey("The snapshot has the state at the time it was captured", func() {
+			So(snapshot.Position(), ShouldEqual, 3)
+			So(snapshot.InstructionCount(), ShouldEqual, 3)
+		})
+
+		Convey("A resumed fork ends like a complete run", func() {
+			f := i.Resume(snapshot.Fork())
+			complete := i.Run(code, state)
+			So(f.HaltReason(), ShouldEqual, HaltCompleted)
+			So(f.InstructionCount(), ShouldEqual, complete.InstructionCount())
+			So(f.Stack("integer").Elements(), ShouldResemble, complete.Stack("integer").Elements())
+			So(f.Loops().IsEmpty(), ShouldBeTrue)
+		})
+
+		Convey("Forks don't affect each other", func() {
+			f1 := snapshot.Fork()
+			f2 := snapshot.Fork()
+			f2.Stack("integer").Push(int64(100))
+			i.Resume(f1)
+			i.Resume(f2)
+			So(f2.Stack("integer").Elements(), ShouldNotResemble, f1.Stack("integer").Elements())
+			So(snapshot.Fork().Stack("integer").Elements(), ShouldResemble, Elements{int64(7), int64(8), int64(3)})
+		})
+
+		Convey("Running a fork doesn't change the original", func() {
+			i.Resume(half.Fork())
+			So(half.Stack("integer").Elements(), ShouldResemble, Elements{int64(7), int64(8), int64(3)})
+			So(half.Cursor().Position, ShouldEqual, 3)
+			So(half.HaltReason(), ShouldEqual, NotHalted)
+		})
+	})
+}
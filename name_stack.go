@@ -0,0 +1,68 @@
+package spogoto
+
+import (
+	"regexp"
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z_]\w*$`)
+
+// NewNameStack generates a name DataStack. Names are symbols that are not
+// recognized as any other Instruction. Executing a name that has been
+// bound with a define function executes its binding, otherwise the name is
+// pushed to the name stack.
+func NewNameStack(names []string) *datastack {
+	elements := Elements{}
+	for _, v := range names {
+		elements = append(elements, v)
+	}
+	d := NewDataStack(elements, FunctionMap{}, func(str string) (Element, bool) {
+		return Element(str), namePattern.MatchString(str)
+	})
+	addNameFunctions(d)
+	return d
+}
+
+// NameStackConstructor creates an empty name stack.
+func NameStackConstructor() (string, DataStack) {
+	return "name", NewNameStack([]string{})
+}
+
+func addNameFunctions(ds *datastack) {
+
+	// Names are not values that can be bound to other names
+	delete(ds.FunctionMap, "define")
+
+	ds.FunctionMap["quote"] = func(d DataStack, r RunSet, i Interpreter) {
+		args, ok := execArgs(r, 1)
+		if !ok {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		if args[0].Type == "name" && args[0].Function == "" {
+			d.Push(args[0].Value)
+		} else {
+			pushExec(r.Stack("exec"), args[0])
+		}
+	}
+
+	ds.FunctionMap["="] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(2) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		r.Stack("boolean").Push(d.Pop().(string) == d.Pop().(string))
+	}
+
+	ds.FunctionMap["isbound"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		_, ok := r.Binding(d.Pop().(string))
+		r.Stack("boolean").Push(ok)
+	}
+
+}
@@ -0,0 +1,58 @@
+package spogoto
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestNameStackFunctions(t *testing.T) {
+	testData := []struct {
+		code       string
+		namesAfter []string
+		intsAfter  []int64
+		boolsAfter []bool
+	}{
+		{"foo bar", []string{"foo", "bar"}, []int64{}, []bool{}},
+		{"x 5 integer.define x x integer.+", []string{}, []int64{10}, []bool{}},
+		{"x 5 integer.define name.quote x", []string{"x"}, []int64{}, []bool{}},
+		{"name.quote 3", []string{}, []int64{3}, []bool{}},
+		{"x 2.5 float.define y true boolean.define x y", []string{}, []int64{}, []bool{true}},
+		{`greeting "hi" string.define greeting string.length`, []string{}, []int64{2}, []bool{}},
+		{"sq code.quote (integer.dup integer.*) code.define 3 sq", []string{}, []int64{9}, []bool{}},
+		{"x [1 2] vector_integer.define x vector_integer.sum", []string{}, []int64{3}, []bool{}},
+		{"5 integer.define", []string{}, []int64{5}, []bool{}},
+		{"x integer.define", []string{"x"}, []int64{}, []bool{}},
+		{"x 1 integer.define name.quote x y name.isbound name.isbound", []string{}, []int64{}, []bool{false, true}},
+		{"a a name.= a b name.=", []string{}, []int64{}, []bool{true, false}},
+	}
+
+	for _, d := range testData {
+		Convey(fmt.Sprintf("Running code `%s`", d.code), t, func() {
+			i := NewInterpreter(DefaultOptions)
+			r := i.Run(CodeFromString(d.code), StackState{})
+
+			Convey(fmt.Sprintf("Name stack should be %v", d.namesAfter), func() {
+				So(r.Stack("name").Elements(), ShouldResemble, stringElements(d.namesAfter))
+				So(r.Stack("integer").Elements(), ShouldResemble, int64Elements(d.intsAfter))
+				So(r.Stack("boolean").Elements(), ShouldResemble, boolElements(d.boolsAfter))
+			})
+		})
+	}
+
+	Convey("Given a RunSet with bindings", t, func() {
+		i := NewInterpreter(DefaultOptions)
+		r := i.Run(CodeFromString("x 5 integer.define"), StackState{})
+
+		Convey("Bindings can be retrieved", func() {
+			in, ok := r.Binding("x")
+			So(ok, ShouldBeTrue)
+			So(in, ShouldResemble, NewInstruction("integer", "5", ""))
+		})
+
+		Convey("Bindings are not shared between runs", func() {
+			_, ok := i.Run(Code{}, StackState{}).Binding("x")
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
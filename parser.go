@@ -2,27 +2,86 @@ package spogoto
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
 )
 
 // Instruction is a unit of code signifying the type it operates on,
 // the value literal of the instruction, the function to call if present,
 // and the number of runs or executions that the instruction has been called.
+// A block Instruction holds the Instructions enclosed in parentheses.
 type Instruction struct {
 	Type     string
 	Value    string
 	Function string
 	Runs     int
+	Block    InstructionSet
 }
 
 // NewInstruction creates a new Instruction.
 func NewInstruction(t string, val string, fn string) Instruction {
-	return Instruction{t, val, fn, 0}
+	return Instruction{t, val, fn, 0, nil}
+}
+
+// NewBlock creates a block Instruction out of instructions. Executing a
+// block pushes its Instructions to the exec stack.
+func NewBlock(instructions InstructionSet) Instruction {
+	if instructions == nil {
+		instructions = InstructionSet{}
+	}
+	b := Instruction{Type: "exec", Block: instructions}
+	b.Value = b.Code().String()
+	return b
+}
+
+// IsBlock returns true if the Instruction is a block.
+func (in Instruction) IsBlock() bool {
+	return in.Block != nil
+}
+
+// Code returns the Code the Instruction was parsed from.
+func (in Instruction) Code() Code {
+	if !in.IsBlock() {
+		return Code{in.Value}
+	}
+	code := Code{"("}
+	code = append(code, in.Block.Code()...)
+	return append(code, ")")
+}
+
+func (in Instruction) String() string {
+	return in.Code().String()
 }
 
 // InstructionSet is a list of Instructions.
 type InstructionSet []Instruction
 
+// Code returns the Code the InstructionSet was parsed from.
+func (s InstructionSet) Code() Code {
+	code := Code{}
+	for _, in := range s {
+		code = append(code, in.Code()...)
+	}
+	return code
+}
+
+func (s InstructionSet) String() string {
+	return s.Code().String()
+}
+
+// Size returns the number of Instructions in the InstructionSet including
+// the Instructions inside blocks. Each block also counts as one.
+func (s InstructionSet) Size() int64 {
+	var size int64
+	for _, in := range s {
+		size++
+		if in.IsBlock() {
+			size += in.Block.Size()
+		}
+	}
+	return size
+}
+
 // Parser parses string codes into an InstructionSet.
 type Parser struct {
 	Functions map[string]map[string]bool
@@ -38,24 +97,54 @@ func (p *Parser) FunctionRegistered(t string, fn string) bool {
 	return false
 }
 
-// Parse parses string into an InstructionSet.
+// Parse parses string into an InstructionSet. Items enclosed in
+// parentheses are parsed into block Instructions. Unmatched closing
+// parentheses are ignored and unclosed blocks end with the code.
 func (p *Parser) Parse(code Code) InstructionSet {
 	i := InstructionSet{}
-	for _, item := range code {
-		parsed := p.ParseItem(item)
-		if parsed.Type != "" {
-			i = append(i, parsed)
-		}
+	for k := 0; k < len(code); k++ {
+		// parseBlock stops on an unmatched closing parenthesis which is skipped
+		parsed, end := p.parseBlock(code, k)
+		i = append(i, parsed...)
+		k = end
 	}
 	return i
 }
 
+// parseBlock parses code starting at index start until the parenthesis
+// closing the block. It returns the block and the index where it ends.
+func (p *Parser) parseBlock(code Code, start int) (InstructionSet, int) {
+	i := InstructionSet{}
+	for k := start; k < len(code); k++ {
+		switch code[k] {
+		case ")":
+			return i, k
+		case "(":
+			block, end := p.parseBlock(code, k+1)
+			i = append(i, NewBlock(block))
+			k = end
+		default:
+			parsed := p.ParseItem(code[k])
+			if parsed.Type != "" {
+				i = append(i, parsed)
+			}
+		}
+	}
+	return i, len(code)
+}
+
 // ParseItem parses a single string instruction into an Instruciton.
 func (p *Parser) ParseItem(item string) Instruction {
 	var t string
 	var fn string
 	if item == "true" || item == "false" {
 		t = "boolean"
+	} else if isStringLiteral(item) {
+		t = "string"
+	} else if _, ok := parseChar(item); ok {
+		t = "char"
+	} else if strings.HasPrefix(item, "[") {
+		t = vectorLiteralType(item)
 	} else if regexp.MustCompile(`^\-?\d+$`).MatchString(item) {
 		t = "integer"
 	} else if regexp.MustCompile(`^\-?\d+\.\d+$`).MatchString(item) {
@@ -69,11 +158,41 @@ func (p *Parser) ParseItem(item string) Instruction {
 			fn = ""
 		}
 
+	} else if p.TypeRegistered("name") && namePattern.MatchString(item) {
+		t = "name"
 	}
 
 	return NewInstruction(t, item, fn)
 }
 
+// TypeRegistered returns true if functions of a type t have been
+// registered.
+func (p *Parser) TypeRegistered(t string) bool {
+	_, ok := p.Functions[t]
+	return ok
+}
+
+// isStringLiteral returns true if item is a valid double quoted string.
+func isStringLiteral(item string) bool {
+	if !strings.HasPrefix(item, "\"") {
+		return false
+	}
+	_, err := strconv.Unquote(item)
+	return err == nil
+}
+
+// vectorLiteralType returns the type of the vector literal item or an
+// empty string if item is not a valid vector literal. An empty vector
+// literal is a vector_integer.
+func vectorLiteralType(item string) string {
+	for _, t := range []string{"vector_integer", "vector_float", "vector_boolean"} {
+		if _, ok := parseVector(item, vectorScalars[t]); ok {
+			return t
+		}
+	}
+	return ""
+}
+
 // RegisterFunction registers a function of a type t and adds it to its
 // list of available functions.
 func (p *Parser) RegisterFunction(t string, fn string) {
@@ -55,6 +55,17 @@ func TestParser(t *testing.T) {
 			)
 		})
 
+		Convey("It can parse string literals", func() {
+			code := Code{`"foo bar"`, `"a.b"`, `"unterminated`}
+			So(
+				parser.Parse(code), ShouldResemble,
+				InstructionSet{
+					NewInstruction("string", `"foo bar"`, ""),
+					NewInstruction("string", `"a.b"`, ""),
+				},
+			)
+		})
+
 		Convey("It can parse known data stack functions", func() {
 			code := CodeFromString("foo.bar foo.baz foo.+")
 			So(
@@ -76,5 +87,48 @@ func TestParser(t *testing.T) {
 				},
 			)
 		})
+
+		Convey("It can parse nested blocks", func() {
+			code := CodeFromString("1 (foo.bar (2)) foo.baz")
+			So(
+				parser.Parse(code), ShouldResemble,
+				InstructionSet{
+					NewInstruction("integer", "1", ""),
+					NewBlock(InstructionSet{
+						NewInstruction("foo", "foo.bar", "bar"),
+						NewBlock(InstructionSet{NewInstruction("integer", "2", "")}),
+					}),
+					NewInstruction("foo", "foo.baz", "baz"),
+				},
+			)
+		})
+
+		Convey("It ignores unmatched closing parentheses", func() {
+			code := CodeFromString(") 1 ) 2")
+			So(
+				parser.Parse(code), ShouldResemble,
+				InstructionSet{
+					NewInstruction("integer", "1", ""),
+					NewInstruction("integer", "2", ""),
+				},
+			)
+		})
+
+		Convey("It closes unclosed blocks at the end of the code", func() {
+			code := CodeFromString("1 (2 (3")
+			So(parser.Parse(code).String(), ShouldEqual, "1 (2 (3))")
+		})
+
+		Convey("It parses empty blocks", func() {
+			parsed := parser.Parse(CodeFromString("()"))
+			So(parsed[0].IsBlock(), ShouldBeTrue)
+			So(parsed[0].Value, ShouldEqual, "()")
+		})
+
+		Convey("The size of parsed code counts blocks and their contents", func() {
+			So(parser.Parse(CodeFromString("1 foo.baz")).Size(), ShouldEqual, 2)
+			So(parser.Parse(CodeFromString("1 (2 (3) ()) unknown")).Size(), ShouldEqual, 6)
+			So(parser.Parse(Code{}).Size(), ShouldEqual, 0)
+		})
 	})
 }
@@ -0,0 +1,27 @@
+package problems
+
+import (
+	"fmt"
+	"github.com/asartalo/spogoto"
+	"github.com/asartalo/spogoto/gp"
+)
+
+// EvenParity is the problem of telling whether an even number of n
+// booleans are true. The training and test cases are all the
+// combinations of the booleans.
+func EvenParity(n int) Problem {
+	cases := []gp.Case{}
+	for bits := 0; bits < 1<<uint(n); bits++ {
+		inputs := []spogoto.Element{}
+		even := true
+		for k := 0; k < n; k++ {
+			b := bits&(1<<uint(k)) != 0
+			inputs = append(inputs, b)
+			even = even != b
+		}
+		cases = append(cases, newCase(even, inputs...))
+	}
+	return Problem{
+		fmt.Sprintf("even-%d-parity", n), options(int64(n)), cases, cases, booleanMatch,
+	}
+}
@@ -0,0 +1,31 @@
+package problems
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestEvenParity(t *testing.T) {
+	Convey("Given the even-3-parity problem", t, func() {
+		p := EvenParity(3)
+
+		Convey("It has all combinations of booleans", func() {
+			So(p.Name, ShouldEqual, "even-3-parity")
+			So(p.Train, ShouldHaveLength, 8)
+			So(p.Train[0].Expected[0], ShouldEqual, true)
+			So(p.Train[1].Expected[0], ShouldEqual, false)
+			So(p.Train[7].Expected[0], ShouldEqual, false)
+		})
+
+		Convey("A correct program has no error", func() {
+			train, test := totalError(p, "boolean.= boolean.= boolean.not")
+			So(train, ShouldEqual, 0)
+			So(test, ShouldEqual, 0)
+		})
+
+		Convey("A wrong program has errors", func() {
+			train, _ := totalError(p, "boolean.and boolean.and")
+			So(train, ShouldBeGreaterThan, 0)
+		})
+	})
+}
@@ -0,0 +1,150 @@
+// Package problems provides benchmark problems for evolving Spogoto code.
+package problems
+
+import (
+	"github.com/asartalo/spogoto"
+	"github.com/asartalo/spogoto/gp"
+	"math"
+)
+
+// penalty is the error of a case when a program leaves no result.
+const penalty = 1000.0
+
+// Problem is a benchmark problem. Programs are trained on the Train cases
+// and compared on the Test cases. Options configure an Interpreter with
+// the inputs of the problem.
+type Problem struct {
+	Name    string
+	Options spogoto.Options
+	Train   []gp.Case
+	Test    []gp.Case
+	Error   gp.CaseError
+}
+
+// Fitness returns a Fitness that measures errors on the Train cases.
+func (p Problem) Fitness() gp.Fitness {
+	return gp.CaseFitness(p.Train, p.Error)
+}
+
+// TestFitness returns a Fitness that measures errors on the Test cases.
+func (p Problem) TestFitness() gp.Fitness {
+	return gp.CaseFitness(p.Test, p.Error)
+}
+
+// All returns all the benchmark problems.
+func All() []Problem {
+	return []Problem{
+		Quartic(), Koza2(), Koza3(), EvenParity(3), EvenParity(4), NumberIO(),
+		Smallest(), Median(), SmallOrLarge(), CompareStringLengths(),
+		LastIndexOfZero(), VectorAverage(),
+	}
+}
+
+// newCase creates a case with the inputs on their stacks and available to
+// the in.N Instructions. The last input is on top of its stack.
+func newCase(expected spogoto.Element, inputs ...spogoto.Element) gp.Case {
+	c := gp.Case{
+		State:    spogoto.StackState{},
+		Inputs:   spogoto.Inputs(inputs),
+		Expected: spogoto.Elements{expected},
+	}
+	for _, input := range inputs {
+		t, _ := spogoto.ElementType(input)
+		c.State[t] = append(c.State[t], input)
+	}
+	return c
+}
+
+// options returns the default Interpreter options with n inputs.
+func options(n int64) spogoto.Options {
+	o := spogoto.DefaultOptions
+	o.Inputs = n
+	return o
+}
+
+// floatError is the distance of the top float from the expected float.
+func floatError(c gp.Case, r spogoto.RunSet) float64 {
+	top, ok := r.TopFloat()
+	if !ok {
+		return penalty
+	}
+	return math.Abs(top - c.Expected[0].(float64))
+}
+
+// integerError is the distance of the top integer from the expected
+// integer.
+func integerError(c gp.Case, r spogoto.RunSet) float64 {
+	top, ok := r.TopInteger()
+	if !ok {
+		return penalty
+	}
+	return math.Abs(float64(top - c.Expected[0].(int64)))
+}
+
+// integerMatch is 0 if the top integer is the expected integer and 1
+// otherwise.
+func integerMatch(c gp.Case, r spogoto.RunSet) float64 {
+	top, ok := r.TopInteger()
+	if !ok || top != c.Expected[0].(int64) {
+		return 1
+	}
+	return 0
+}
+
+// booleanMatch is 0 if the top boolean is the expected boolean and 1
+// otherwise.
+func booleanMatch(c gp.Case, r spogoto.RunSet) float64 {
+	top, ok := r.TopBool()
+	if !ok || top != c.Expected[0].(bool) {
+		return 1
+	}
+	return 0
+}
+
+// stringError is the edit distance of the top string from the expected
+// string. An empty string stack counts as the empty string so that cases
+// expecting nothing are solved by printing nothing.
+func stringError(c gp.Case, r spogoto.RunSet) float64 {
+	top, _ := r.Stack("string").Peek().(string)
+	return float64(levenshtein(top, c.Expected[0].(string)))
+}
+
+// levenshtein returns the number of rune insertions, deletions and
+// substitutions needed to change a into b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	for k := range previous {
+		previous[k] = k
+	}
+	for x := 1; x <= len(ra); x++ {
+		current := make([]int, len(rb)+1)
+		current[0] = x
+		for y := 1; y <= len(rb); y++ {
+			cost := 1
+			if ra[x-1] == rb[y-1] {
+				cost = 0
+			}
+			current[y] = minInt(minInt(previous[y]+1, current[y-1]+1), previous[y-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(rb)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// randomInt returns a random integer from min to max.
+func randomInt(r spogoto.Rand, min, max int64) int64 {
+	return min + r.Int63n(max-min+1)
+}
+
+// randomFloat returns a random float from min up to but not including max.
+func randomFloat(r spogoto.Rand, min, max float64) float64 {
+	return min + r.Float64()*(max-min)
+}
@@ -0,0 +1,91 @@
+package problems
+
+import (
+	"github.com/asartalo/spogoto"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+// totalError returns the total training and test errors of code on p.
+func totalError(p Problem, code string) (float64, float64) {
+	i := spogoto.NewInterpreter(p.Options)
+	sum := func(errors []float64) float64 {
+		total := 0.0
+		for _, e := range errors {
+			total += e
+		}
+		return total
+	}
+	c := spogoto.CodeFromString(code)
+	return sum(p.Fitness()(i, c)), sum(p.TestFitness()(i, c))
+}
+
+func TestProblems(t *testing.T) {
+	Convey("Given all the problems", t, func() {
+		all := All()
+
+		Convey("They have unique names", func() {
+			names := map[string]bool{}
+			for _, p := range all {
+				names[p.Name] = true
+			}
+			So(names, ShouldHaveLength, len(all))
+		})
+
+		Convey("They have training and test cases with their inputs", func() {
+			for _, p := range all {
+				So(p.Train, ShouldNotBeEmpty)
+				So(p.Test, ShouldNotBeEmpty)
+				So(p.Train[0].Inputs, ShouldHaveLength, p.Options.Inputs)
+				So(p.Train[0].Expected, ShouldHaveLength, 1)
+			}
+		})
+
+		Convey("Programs that do nothing have errors", func() {
+			for _, p := range all {
+				train, test := totalError(p, "")
+				So(train, ShouldBeGreaterThan, 0)
+				So(test, ShouldBeGreaterThan, 0)
+			}
+		})
+
+		Convey("The cases are the same every time", func() {
+			So(Smallest().Test, ShouldResemble, Smallest().Test)
+		})
+	})
+
+	Convey("Given inputs of a case", t, func() {
+		c := newCase(int64(3), int64(1), 2.5, int64(2), true)
+
+		Convey("They are on the stacks of their types", func() {
+			So(c.State, ShouldResemble, spogoto.StackState{
+				"integer": spogoto.Elements{int64(1), int64(2)},
+				"float":   spogoto.Elements{2.5},
+				"boolean": spogoto.Elements{true},
+			})
+		})
+
+		Convey("They are available to in.N", func() {
+			So(c.Inputs, ShouldResemble, spogoto.Inputs{int64(1), 2.5, int64(2), true})
+		})
+	})
+
+	Convey("Given a case expecting an empty string", t, func() {
+		c := newCase("", int64(1500))
+		i := spogoto.NewInterpreter(SmallOrLarge().Options)
+
+		Convey("An empty string stack has no error", func() {
+			So(stringError(c, i.Run(spogoto.Code{}, c.State)), ShouldEqual, 0)
+		})
+
+		Convey("Other strings are compared to it", func() {
+			So(stringError(c, i.Run(spogoto.CodeFromString(`"small"`), c.State)), ShouldEqual, 5)
+		})
+	})
+
+	Convey("The edit distance counts the changes between strings", t, func() {
+		So(levenshtein("", ""), ShouldEqual, 0)
+		So(levenshtein("small", ""), ShouldEqual, 5)
+		So(levenshtein("kitten", "sitting"), ShouldEqual, 3)
+	})
+}
@@ -0,0 +1,190 @@
+package problems
+
+import (
+	"github.com/asartalo/spogoto"
+	"github.com/asartalo/spogoto/gp"
+)
+
+// The problems in this file are from the General Program Synthesis
+// Benchmark Suite (PSB1) by Helmuth and Spector.
+
+// NumberIO is the problem of adding an integer and a float.
+func NumberIO() Problem {
+	generate := func(r spogoto.Rand) gp.Case {
+		n := randomInt(r, -100, 100)
+		x := randomFloat(r, -100, 100)
+		return newCase(float64(n)+x, n, x)
+	}
+	train, test := generateCases(2, 25, 1000, generate)
+	return Problem{"number-io", options(2), train, test, floatError}
+}
+
+// Smallest is the problem of finding the smallest of four integers.
+func Smallest() Problem {
+	generate := func(r spogoto.Rand) gp.Case {
+		inputs := []spogoto.Element{}
+		smallest := int64(101)
+		for k := 0; k < 4; k++ {
+			n := randomInt(r, -100, 100)
+			if n < smallest {
+				smallest = n
+			}
+			inputs = append(inputs, n)
+		}
+		return newCase(smallest, inputs...)
+	}
+	train, test := generateCases(3, 100, 1000, generate,
+		newCase(int64(0), int64(0), int64(0), int64(0), int64(0)),
+		newCase(int64(-100), int64(-100), int64(100), int64(100), int64(100)),
+		newCase(int64(-7), int64(5), int64(5), int64(5), int64(-7)),
+	)
+	return Problem{"smallest", options(4), train, test, integerMatch}
+}
+
+// Median is the problem of finding the median of three integers.
+func Median() Problem {
+	generate := func(r spogoto.Rand) gp.Case {
+		a, b, c := randomInt(r, -100, 100), randomInt(r, -100, 100), randomInt(r, -100, 100)
+		if r.Int63n(4) == 0 {
+			b = a
+		}
+		return newCase(median(a, b, c), a, b, c)
+	}
+	train, test := generateCases(4, 100, 1000, generate,
+		newCase(int64(3), int64(3), int64(3), int64(3)),
+		newCase(int64(100), int64(100), int64(-100), int64(100)),
+	)
+	return Problem{"median", options(3), train, test, integerMatch}
+}
+
+// SmallOrLarge is the problem of printing "small" if an integer is below
+// 1000, "large" if it is 2000 or more and nothing otherwise.
+func SmallOrLarge() Problem {
+	classify := func(n int64) gp.Case {
+		expected := ""
+		if n < 1000 {
+			expected = "small"
+		} else if n >= 2000 {
+			expected = "large"
+		}
+		return newCase(expected, n)
+	}
+	generate := func(r spogoto.Rand) gp.Case {
+		return classify(randomInt(r, -10000, 10000))
+	}
+	edges := []gp.Case{}
+	for _, n := range []int64{-10000, 0, 980, 999, 1000, 1001, 1999, 2000, 2001, 2020, 10000} {
+		edges = append(edges, classify(n))
+	}
+	train, test := generateCases(5, 100, 1000, generate, edges...)
+	return Problem{"small-or-large", options(1), train, test, stringError}
+}
+
+// CompareStringLengths is the problem of telling whether three strings
+// are in strictly increasing order of length.
+func CompareStringLengths() Problem {
+	compare := func(a, b, c string) gp.Case {
+		return newCase(len(a) < len(b) && len(b) < len(c), a, b, c)
+	}
+	generate := func(r spogoto.Rand) gp.Case {
+		return compare(randomString(r, 49), randomString(r, 49), randomString(r, 49))
+	}
+	train, test := generateCases(6, 100, 1000, generate,
+		compare("", "", ""),
+		compare("", "a", "ab"),
+		compare("ab", "a", ""),
+		compare("a", "bc", "de"),
+	)
+	return Problem{"compare-string-lengths", options(3), train, test, booleanMatch}
+}
+
+// LastIndexOfZero is the problem of finding the index of the last zero in
+// a vector of integers.
+func LastIndexOfZero() Problem {
+	generate := func(r spogoto.Rand) gp.Case {
+		v := make([]int64, randomInt(r, 1, 50))
+		for k := range v {
+			if r.Int63n(3) > 0 {
+				v[k] = randomInt(r, -50, 50)
+			}
+		}
+		v[r.Int63n(int64(len(v)))] = 0
+		return newCase(lastIndexOfZero(v), v)
+	}
+	train, test := generateCases(7, 150, 1000, generate,
+		newCase(int64(0), []int64{0}),
+		newCase(int64(4), []int64{0, 0, 0, 0, 0}),
+		newCase(int64(0), []int64{0, 5, -8, 9}),
+	)
+	return Problem{"last-index-of-zero", options(1), train, test, integerError}
+}
+
+// VectorAverage is the problem of finding the average of a vector of
+// floats.
+func VectorAverage() Problem {
+	average := func(v []float64) gp.Case {
+		sum := 0.0
+		for _, x := range v {
+			sum += x
+		}
+		return newCase(sum/float64(len(v)), v)
+	}
+	generate := func(r spogoto.Rand) gp.Case {
+		v := make([]float64, randomInt(r, 1, 50))
+		for k := range v {
+			v[k] = randomFloat(r, -1000, 1000)
+		}
+		return average(v)
+	}
+	train, test := generateCases(8, 100, 1000, generate,
+		average([]float64{0}),
+		average([]float64{-1000, 1000}),
+		average([]float64{1.5}),
+	)
+	return Problem{"vector-average", options(1), train, test, floatError}
+}
+
+// generateCases creates training and test cases with a generator seeded
+// with seed. The edge cases are part of the training cases.
+func generateCases(seed int64, train int, test int, generate func(spogoto.Rand) gp.Case, edges ...gp.Case) ([]gp.Case, []gp.Case) {
+	r := spogoto.NewSplitMix64(seed)
+	trainCases := append([]gp.Case{}, edges...)
+	for len(trainCases) < train {
+		trainCases = append(trainCases, generate(r))
+	}
+	testCases := []gp.Case{}
+	for len(testCases) < test {
+		testCases = append(testCases, generate(r))
+	}
+	return trainCases, testCases
+}
+
+func median(a, b, c int64) int64 {
+	if (a <= b && b <= c) || (c <= b && b <= a) {
+		return b
+	}
+	if (b <= a && a <= c) || (c <= a && a <= b) {
+		return a
+	}
+	return c
+}
+
+func lastIndexOfZero(v []int64) int64 {
+	for k := len(v) - 1; k >= 0; k-- {
+		if v[k] == 0 {
+			return int64(k)
+		}
+	}
+	return -1
+}
+
+// randomString returns a random string of lowercase letters and spaces of
+// up to max runes.
+func randomString(r spogoto.Rand, max int64) string {
+	letters := []rune("abcdefghijklmnopqrstuvwxyz ")
+	runes := make([]rune, r.Int63n(max+1))
+	for k := range runes {
+		runes[k] = letters[r.Int63n(int64(len(letters)))]
+	}
+	return string(runes)
+}
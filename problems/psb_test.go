@@ -0,0 +1,64 @@
+package problems
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestPSB(t *testing.T) {
+	Convey("Correct programs have no error", t, func() {
+		data := []struct {
+			problem Problem
+			code    string
+		}{
+			{NumberIO(), "float.frominteger float.+"},
+			{Smallest(), "integer.min integer.min integer.min"},
+			{
+				VectorAverage(),
+				"vector_float.dup vector_float.sum vector_float.length float.frominteger float./",
+			},
+		}
+
+		for _, d := range data {
+			train, test := totalError(d.problem, d.code)
+			So(train, ShouldBeLessThan, 1e-6)
+			So(test, ShouldBeLessThan, 1e-6)
+		}
+	})
+
+	Convey("The cases have the expected results", t, func() {
+		So(median(1, 2, 3), ShouldEqual, 2)
+		So(median(3, 1, 2), ShouldEqual, 2)
+		So(median(2, 3, 1), ShouldEqual, 2)
+		So(median(5, 5, 1), ShouldEqual, 5)
+		So(lastIndexOfZero([]int64{0, 1, 0, 2}), ShouldEqual, 2)
+
+		for _, c := range SmallOrLarge().Train {
+			n := c.Inputs[0].(int64)
+			switch {
+			case n < 1000:
+				So(c.Expected[0], ShouldEqual, "small")
+			case n >= 2000:
+				So(c.Expected[0], ShouldEqual, "large")
+			default:
+				So(c.Expected[0], ShouldEqual, "")
+			}
+		}
+
+		for _, c := range LastIndexOfZero().Train {
+			v := c.Inputs[0].([]int64)
+			So(v[c.Expected[0].(int64)], ShouldEqual, 0)
+		}
+
+		for _, c := range CompareStringLengths().Train {
+			So(len(c.Inputs[0].(string)), ShouldBeLessThanOrEqualTo, 49)
+		}
+	})
+
+	Convey("The PSB problems have the suite's numbers of cases", t, func() {
+		So(NumberIO().Train, ShouldHaveLength, 25)
+		So(Median().Train, ShouldHaveLength, 100)
+		So(LastIndexOfZero().Train, ShouldHaveLength, 150)
+		So(Smallest().Test, ShouldHaveLength, 1000)
+	})
+}
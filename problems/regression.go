@@ -0,0 +1,46 @@
+package problems
+
+import (
+	"github.com/asartalo/spogoto"
+	"github.com/asartalo/spogoto/gp"
+)
+
+// Quartic is the symbolic regression of x^4 + x^3 + x^2 + x, also known as
+// Koza-1.
+func Quartic() Problem {
+	return regression("quartic", func(x float64) float64 {
+		return x*x*x*x + x*x*x + x*x + x
+	})
+}
+
+// Koza2 is the symbolic regression of x^5 - 2x^3 + x.
+func Koza2() Problem {
+	return regression("koza-2", func(x float64) float64 {
+		return x*x*x*x*x - 2*x*x*x + x
+	})
+}
+
+// Koza3 is the symbolic regression of x^6 - 2x^4 + x^2.
+func Koza3() Problem {
+	return regression("koza-3", func(x float64) float64 {
+		return x*x*x*x*x*x - 2*x*x*x*x + x*x
+	})
+}
+
+// regression creates a symbolic regression problem of f. The input x is
+// from -1 to 1. There are 20 random training cases and 100 evenly spaced
+// test cases.
+func regression(name string, f func(float64) float64) Problem {
+	r := spogoto.NewSplitMix64(1)
+	train := []gp.Case{}
+	for k := 0; k < 20; k++ {
+		x := randomFloat(r, -1, 1)
+		train = append(train, newCase(f(x), x))
+	}
+	test := []gp.Case{}
+	for k := 0; k < 100; k++ {
+		x := -1 + 2*float64(k)/99
+		test = append(test, newCase(f(x), x))
+	}
+	return Problem{name, options(1), train, test, floatError}
+}
@@ -0,0 +1,34 @@
+package problems
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestRegression(t *testing.T) {
+	Convey("Given the quartic problem", t, func() {
+		p := Quartic()
+
+		Convey("Its cases are within -1 and 1", func() {
+			So(p.Train, ShouldHaveLength, 20)
+			So(p.Test, ShouldHaveLength, 100)
+			So(p.Test[0].Inputs[0], ShouldEqual, -1.0)
+			So(p.Test[99].Inputs[0], ShouldEqual, 1.0)
+			So(p.Test[99].Expected[0], ShouldEqual, 4.0)
+		})
+
+		Convey("A correct program has no error", func() {
+			train, test := totalError(p,
+				"in.0 1.0 float.+ in.0 float.* 1.0 float.+ in.0 float.* 1.0 float.+ in.0 float.*",
+			)
+			So(train, ShouldBeLessThan, 1e-9)
+			So(test, ShouldBeLessThan, 1e-9)
+		})
+	})
+
+	Convey("The Koza problems have their own targets", t, func() {
+		So(Koza2().Test[99].Expected[0], ShouldEqual, 0.0)
+		So(Koza3().Test[0].Expected[0], ShouldEqual, 0.0)
+		So(Koza2().Test[0].Expected[0], ShouldEqual, 0.0)
+	})
+}
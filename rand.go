@@ -0,0 +1,70 @@
+package spogoto
+
+// golden is the increment of SplitMix64, the odd integer closest to
+// 2^64 divided by the golden ratio.
+const golden = 0x9e3779b97f4a7c15
+
+// SplitMix64 is a small and fast Rand that can be split into independent
+// generators. The same seed always generates the same values.
+type SplitMix64 struct {
+	state uint64
+}
+
+// NewSplitMix64 constructs a new SplitMix64 seeded with seed.
+func NewSplitMix64(seed int64) *SplitMix64 {
+	return &SplitMix64{uint64(seed)}
+}
+
+// Uint64 generates a random 64-bit integer.
+func (s *SplitMix64) Uint64() uint64 {
+	s.state += golden
+	return mix64(s.state)
+}
+
+// Int63 generates a random non-negative int64.
+func (s *SplitMix64) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// Int63n generates a random integer from 0 to n - 1. It panics if n is not
+// positive.
+func (s *SplitMix64) Int63n(n int64) int64 {
+	if n <= 0 {
+		panic("invalid argument to Int63n")
+	}
+	max := int64((1 << 63) - 1 - (1<<63)%uint64(n))
+	v := s.Int63()
+	for v > max {
+		v = s.Int63()
+	}
+	return v % n
+}
+
+// Float64 generates a random float number from 0 up to but not including 1.
+func (s *SplitMix64) Float64() float64 {
+	return float64(s.Uint64()>>11) / (1 << 53)
+}
+
+// Seed resets the generator to the state of a new one seeded with seed.
+func (s *SplitMix64) Seed(seed int64) {
+	s.state = uint64(seed)
+}
+
+// State returns the state of the generator. A generator seeded with the
+// state generates the same values as this one.
+func (s *SplitMix64) State() int64 {
+	return int64(s.state)
+}
+
+// Split returns a new SplitMix64 with a stream of values that is
+// independent of the values of s. Splitting advances s.
+func (s *SplitMix64) Split() *SplitMix64 {
+	return &SplitMix64{mix64(s.Uint64())}
+}
+
+// mix64 scrambles the bits of z.
+func mix64(z uint64) uint64 {
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
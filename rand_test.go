@@ -0,0 +1,53 @@
+package spogoto
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestSplitMix64(t *testing.T) {
+	Convey("Given a SplitMix64", t, func() {
+		s := NewSplitMix64(1234567)
+
+		Convey("It generates the reference values of the seed", func() {
+			So(s.Uint64(), ShouldEqual, uint64(6457827717110365317))
+			So(s.Uint64(), ShouldEqual, uint64(3203168211198807973))
+			So(s.Uint64(), ShouldEqual, uint64(9817491932198370423))
+		})
+
+		Convey("It generates integers within range", func() {
+			for k := 0; k < 100; k++ {
+				So(s.Int63n(7), ShouldBeBetweenOrEqual, 0, 6)
+			}
+			So(func() { s.Int63n(0) }, ShouldPanic)
+		})
+
+		Convey("It generates floats from 0 to 1", func() {
+			for k := 0; k < 100; k++ {
+				f := s.Float64()
+				So(f, ShouldBeGreaterThanOrEqualTo, 0)
+				So(f, ShouldBeLessThan, 1)
+			}
+		})
+
+		Convey("Seeding restarts the values", func() {
+			first := s.Int63()
+			s.Seed(1234567)
+			So(s.Int63(), ShouldEqual, first)
+		})
+
+		Convey("A generator seeded with the state continues the values", func() {
+			s.Uint64()
+			copied := NewSplitMix64(s.State())
+			So(copied.Uint64(), ShouldEqual, s.Uint64())
+		})
+
+		Convey("Split generators are independent", func() {
+			child := s.Split()
+			So(child.Uint64(), ShouldNotEqual, s.Uint64())
+
+			again := NewSplitMix64(1234567)
+			So(again.Split().Uint64(), ShouldEqual, NewSplitMix64(1234567).Split().Uint64())
+		})
+	})
+}
@@ -0,0 +1,113 @@
+package spogoto
+
+import (
+	"fmt"
+)
+
+// HaltReason describes why the execution of code stopped.
+type HaltReason int
+
+const (
+	// NotHalted is the HaltReason of a RunSet that has not finished running.
+	NotHalted HaltReason = iota
+
+	// HaltCompleted means the cursor ran past the last Instruction.
+	HaltCompleted
+
+	// HaltEnd means a cursor.end or cursor.endif stopped the execution.
+	HaltEnd
+
+	// HaltMaxInstructions means the number of executed Instructions went over
+	// Options.MaxInstructions.
+	HaltMaxInstructions
+)
+
+var haltReasonNames = map[HaltReason]string{
+	NotHalted:           "not halted",
+	HaltCompleted:       "completed",
+	HaltEnd:             "end",
+	HaltMaxInstructions: "max instructions",
+}
+
+func (h HaltReason) String() string {
+	name, ok := haltReasonNames[h]
+	if !ok {
+		return fmt.Sprintf("HaltReason(%d)", int(h))
+	}
+	return name
+}
+
+// ErrorKind describes why an Instruction was treated as a noop.
+type ErrorKind int
+
+const (
+	// UnknownInstruction is an item in the Code that the Parser did not
+	// recognize.
+	UnknownInstruction ErrorKind = iota
+
+	// StackUnderflow means a stack did not have enough elements.
+	StackUnderflow
+
+	// DivideByZero means a division or modulo by zero was prevented.
+	DivideByZero
+
+	// InvalidJump means a jump target was outside the InstructionSet.
+	InvalidJump
+
+	// UnmatchedNext means a cursor.next was executed outside of a loop.
+	UnmatchedNext
+
+	// UnmatchedReturn means a cursor.return was executed with no cursor.call
+	// to return to.
+	UnmatchedReturn
+
+	// CallDepthExceeded means a cursor.call would go over
+	// Options.MaxCallDepth.
+	CallDepthExceeded
+
+	// MissingInput means an in.N Instruction had no input to push.
+	MissingInput
+
+	// SizeLimitExceeded means an Instruction would have built an element
+	// larger than the limits in the Options.
+	SizeLimitExceeded
+
+	// LoopInBlock means a cursor.loop or cursor.next was executed from the
+	// exec stack. Loops only work outside of blocks.
+	LoopInBlock
+)
+
+var errorKindNames = map[ErrorKind]string{
+	UnknownInstruction: "unknown instruction",
+	StackUnderflow:     "stack underflow",
+	DivideByZero:       "divide by zero",
+	InvalidJump:        "invalid jump",
+	UnmatchedNext:      "unmatched next",
+	UnmatchedReturn:    "unmatched return",
+	CallDepthExceeded:  "call depth exceeded",
+	MissingInput:       "missing input",
+	SizeLimitExceeded:  "size limit exceeded",
+	LoopInBlock:        "loop in block",
+}
+
+func (k ErrorKind) String() string {
+	name, ok := errorKindNames[k]
+	if !ok {
+		return fmt.Sprintf("ErrorKind(%d)", int(k))
+	}
+	return name
+}
+
+// RunError records an Instruction that did nothing during a run.
+// Index is the Cursor position of the Instruction or -1 if it came from
+// the exec stack. For UnknownInstruction errors it is the index of the
+// item in the Code.
+type RunError struct {
+	Index       int64
+	Instruction string
+	Kind        ErrorKind
+}
+
+func (e RunError) Error() string {
+	return fmt.Sprintf("%s at %d (%s)", e.Kind, e.Index, e.Instruction)
+}
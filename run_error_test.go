@@ -0,0 +1,26 @@
+package spogoto
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestRunError(t *testing.T) {
+	Convey("Given a RunError", t, func() {
+		err := RunError{3, "integer.+", StackUnderflow}
+
+		Convey("Error() describes the kind, index and instruction", func() {
+			So(err.Error(), ShouldEqual, "stack underflow at 3 (integer.+)")
+		})
+	})
+
+	Convey("HaltReason has a readable name", t, func() {
+		So(HaltMaxInstructions.String(), ShouldEqual, "max instructions")
+		So(HaltReason(99).String(), ShouldEqual, "HaltReason(99)")
+	})
+
+	Convey("ErrorKind has a readable name", t, func() {
+		So(InvalidJump.String(), ShouldEqual, "invalid jump")
+		So(ErrorKind(99).String(), ShouldEqual, "ErrorKind(99)")
+	})
+}
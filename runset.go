@@ -1,5 +1,10 @@
 package spogoto
 
+import (
+	"fmt"
+	"strconv"
+)
+
 // CursorCommands are functions that operate on the Cursor manipulating
 // its position.
 type CursorCommands map[string]func(RunSet)
@@ -20,6 +25,24 @@ type RunSet interface {
 	IncrementInstructionCount()
 	InstructionCount() int64
 	InitializeStack(string, Elements)
+	Loops() Stack
+	Returns() Stack
+	MaxCallDepth() int64
+	Bind(string, Instruction)
+	Binding(string) (Instruction, bool)
+	Inputs() Inputs
+	SetOutput(string, Element)
+	Output(string) (Element, bool)
+	TopInteger() (int64, bool)
+	TopFloat() (float64, bool)
+	TopBool() (bool, bool)
+	CurrentIndex() int64
+	Halt(HaltReason)
+	HaltReason() HaltReason
+	Noop(ErrorKind)
+	Errors() []RunError
+	Fork() RunSet
+	Snapshot() Snapshot
 }
 
 // Cursor is a representation of a pointer pointing to the current
@@ -29,11 +52,52 @@ type Cursor struct {
 	Instructions InstructionSet
 }
 
+// Loop is a counted loop started by cursor.loop. Start is the position
+// of the cursor.loop Instruction, Remaining is the number of iterations
+// left and Index is the current iteration starting from 0. Only loops
+// outside of blocks can be started and closed.
+type Loop struct {
+	Start     int64
+	Remaining int64
+	Index     int64
+}
+
+// Label returns the position of the first label Instruction with the name.
+// Only labels outside of blocks can be found.
+func (c *Cursor) Label(name string) (int64, bool) {
+	for k, in := range c.Instructions {
+		if in.Type == "label" && in.Function == name {
+			return int64(k), true
+		}
+	}
+	return 0, false
+}
+
+// HasLabels returns true if there are label Instructions outside of blocks.
+func (c *Cursor) HasLabels() bool {
+	for _, in := range c.Instructions {
+		if in.Type == "label" {
+			return true
+		}
+	}
+	return false
+}
+
 type runset struct {
 	dataStacks       map[string]DataStack
 	cursor           Cursor
+	loops            stack
+	returns          stack
+	maxCallDepth     int64
+	bindings         map[string]Instruction
+	inputs           Inputs
+	outputs          map[string]Element
 	cursorCommands   map[string]func(RunSet)
 	instructionCount int64
+	haltReason       HaltReason
+	errors           []RunError
+	current          Instruction
+	currentIndex     int64
 }
 
 // NewRunSet creates a RunSet.
@@ -44,8 +108,12 @@ func NewRunSet(i Interpreter) *runset {
 		stackType, stack := constructor()
 		dataStacks[stackType] = stack
 	}
-	r := &runset{dataStacks: dataStacks}
-	addCursorCommands(r)
+	r := &runset{
+		dataStacks: dataStacks,
+		bindings:   map[string]Instruction{},
+		outputs:    map[string]Element{},
+	}
+	r.cursorCommands = newCursorCommands()
 
 	return r
 }
@@ -55,6 +123,73 @@ func (r *runset) Cursor() *Cursor {
 	return &r.cursor
 }
 
+// Loops returns the stack of the Loops the Cursor is in.
+func (r *runset) Loops() Stack {
+	return &r.loops
+}
+
+// Returns returns the stack of return addresses of cursor.call
+// Instructions.
+func (r *runset) Returns() Stack {
+	return &r.returns
+}
+
+// MaxCallDepth returns the maximum number of nested cursor.call executions.
+// Zero means there is no limit.
+func (r *runset) MaxCallDepth() int64 {
+	return r.maxCallDepth
+}
+
+// Bind binds a name to an Instruction. Executing the name executes the
+// Instruction.
+func (r *runset) Bind(name string, in Instruction) {
+	r.bindings[name] = in
+}
+
+// Binding returns the Instruction bound to the name.
+func (r *runset) Binding(name string) (Instruction, bool) {
+	in, ok := r.bindings[name]
+	return in, ok
+}
+
+// Inputs returns the inputs of the run.
+func (r *runset) Inputs() Inputs {
+	return r.inputs
+}
+
+// SetOutput sets the value of the output register of type t.
+func (r *runset) SetOutput(t string, value Element) {
+	r.outputs[t] = value
+}
+
+// Output returns the value of the output register of type t. It returns
+// false if the code did not write to the register.
+func (r *runset) Output(t string) (Element, bool) {
+	value, ok := r.outputs[t]
+	return value, ok
+}
+
+// TopInteger returns the top of the integer stack. It returns false if the
+// stack is empty.
+func (r *runset) TopInteger() (int64, bool) {
+	value, ok := r.Stack("integer").Peek().(int64)
+	return value, ok
+}
+
+// TopFloat returns the top of the float stack. It returns false if the
+// stack is empty.
+func (r *runset) TopFloat() (float64, bool) {
+	value, ok := r.Stack("float").Peek().(float64)
+	return value, ok
+}
+
+// TopBool returns the top of the boolean stack. It returns false if the
+// stack is empty.
+func (r *runset) TopBool() (bool, bool) {
+	value, ok := r.Stack("boolean").Peek().(bool)
+	return value, ok
+}
+
 // IncrementInstructionCount increments the InstructionCount of course.
 func (r *runset) IncrementInstructionCount() {
 	r.instructionCount++
@@ -121,15 +256,167 @@ func (r *runset) DataStack(stackType string) DataStack {
 	return r.dataStacks[stackType]
 }
 
+// CurrentIndex returns the Cursor position of the Instruction being
+// executed or -1 if it came from the exec stack.
+func (r *runset) CurrentIndex() int64 {
+	return r.currentIndex
+}
+
+// Halt stops the execution of code recording the reason why it stopped.
+// Instructions pending on the exec stack are discarded.
+func (r *runset) Halt(reason HaltReason) {
+	r.haltReason = reason
+	r.cursor.Position = instructionCount(r)
+	r.Stack("exec").Flush()
+}
+
+// HaltReason returns why the execution stopped or NotHalted if it has not.
+func (r *runset) HaltReason() HaltReason {
+	return r.haltReason
+}
+
+// Noop records that the Instruction being executed did nothing.
+func (r *runset) Noop(kind ErrorKind) {
+	r.errors = append(r.errors, RunError{r.currentIndex, r.current.Value, kind})
+}
+
+// Errors returns the noop events recorded during the run.
+func (r *runset) Errors() []RunError {
+	return r.errors
+}
+
+// Fork returns an independent copy of the RunSet. Changes to the copy,
+// including running it, don't affect the RunSet and the other way around.
+// Elements are shared since they are never changed in place.
+func (r *runset) Fork() RunSet {
+	f := *r
+	f.dataStacks = map[string]DataStack{}
+	for t, s := range r.dataStacks {
+		f.dataStacks[t] = s.Clone()
+	}
+	f.loops = r.loops.clone()
+	f.returns = r.returns.clone()
+	f.bindings = map[string]Instruction{}
+	for name, in := range r.bindings {
+		f.bindings[name] = in
+	}
+	f.outputs = map[string]Element{}
+	for t, value := range r.outputs {
+		f.outputs[t] = value
+	}
+	f.errors = append([]RunError{}, r.errors...)
+	return &f
+}
+
+// Snapshot captures the current state of the RunSet.
+func (r *runset) Snapshot() Snapshot {
+	return Snapshot{r.Fork().(*runset)}
+}
+
+// Snapshot is the captured state of a RunSet. It doesn't change and can
+// be forked any number of times.
+type Snapshot struct {
+	r *runset
+}
+
+// Fork returns a new RunSet with the captured state.
+func (s Snapshot) Fork() RunSet {
+	return s.r.Fork()
+}
+
+// InstructionCount returns the number of Instructions executed when the
+// state was captured.
+func (s Snapshot) InstructionCount() int64 {
+	return s.r.instructionCount
+}
+
+// Position returns the position of the Cursor when the state was
+// captured.
+func (s Snapshot) Position() int64 {
+	return s.r.cursor.Position
+}
+
 func instructionCount(r RunSet) int64 {
 	return int64(len(r.Cursor().Instructions))
 }
 
-func addCursorCommands(rs *runset) {
+// loopEnd returns the position after the cursor.next that closes the
+// loop at the Cursor or the end of the Instructions if there is none.
+func loopEnd(r RunSet) int64 {
+	depth := 0
+	instructions := r.Cursor().Instructions
+	for k := r.Cursor().Position + 1; k < int64(len(instructions)); k++ {
+		if instructions[k].Type != "cursor" {
+			continue
+		}
+		switch instructions[k].Function {
+		case "loop":
+			depth++
+		case "next":
+			if depth == 0 {
+				return k + 1
+			}
+			depth--
+		}
+	}
+	return int64(len(instructions))
+}
+
+// pushInput pushes a copy of the input identified by n to the stack of
+// its type.
+func pushInput(r RunSet, n string) {
+	idx, err := strconv.ParseInt(n, 10, 64)
+	if err != nil || idx < 0 || idx >= int64(len(r.Inputs())) {
+		r.Noop(MissingInput)
+		return
+	}
+
+	input := r.Inputs()[idx]
+	t, ok := ElementType(input)
+	if !ok {
+		r.Noop(MissingInput)
+		return
+	}
+
+	if vector := vectorItems(input); len(vector) > 0 {
+		input = newVector(vectorScalars[t], vector)
+	}
+	r.Stack(t).Push(input)
+}
+
+// labelName returns the name of the label identified by n.
+func labelName(n int64) string {
+	return fmt.Sprintf("L%d", n)
+}
+
+// labelTarget returns the position following the label identified by n.
+// If there are no labels, n is the position itself.
+func labelTarget(r RunSet, n int64) (int64, bool) {
+	if !r.Cursor().HasLabels() {
+		return n, true
+	}
+
+	pos, ok := r.Cursor().Label(labelName(n))
+	return pos + 1, ok
+}
+
+// jumpTo moves the Cursor so that the Instruction at pos is executed next.
+func jumpTo(r RunSet, pos int64) {
+	if pos < 0 || pos > instructionCount(r) {
+		r.Noop(InvalidJump)
+		return
+	}
+	r.Cursor().Position = pos - 1
+}
+
+// newCursorCommands creates the cursor commands. Commands only use the
+// RunSet they are called with so they can be shared by forks.
+func newCursorCommands() CursorCommands {
 	commands := make(CursorCommands)
 
 	commands["skipif"] = func(r RunSet) {
 		if r.Bad("boolean", 1) {
+			r.Noop(StackUnderflow)
 			return
 		}
 		if r.Stack("boolean").Pop().(bool) {
@@ -138,11 +425,12 @@ func addCursorCommands(rs *runset) {
 	}
 
 	commands["end"] = func(r RunSet) {
-		r.Cursor().Position = instructionCount(r)
+		r.Halt(HaltEnd)
 	}
 
 	commands["endif"] = func(r RunSet) {
 		if r.Bad("boolean", 1) {
+			r.Noop(StackUnderflow)
 			return
 		}
 		if r.Stack("boolean").Pop().(bool) {
@@ -152,20 +440,140 @@ func addCursorCommands(rs *runset) {
 
 	commands["goto"] = func(r RunSet) {
 		if r.Bad("integer", 1) {
+			r.Noop(StackUnderflow)
 			return
 		}
-		pos := r.Stack("integer").Pop().(int64)
-		if pos < 0 || pos > instructionCount(r) {
-			return
-		}
-		r.Cursor().Position = int64(pos - 1)
+		jumpTo(r, r.Stack("integer").Pop().(int64))
 	}
 
 	commands["gotoif"] = func(r RunSet) {
-		if r.Ok("boolean", 1) && r.Stack("boolean").Pop().(bool) {
+		if r.Bad("boolean", 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+		if r.Stack("boolean").Pop().(bool) {
 			commands["goto"](r)
 		}
 	}
 
-	rs.cursorCommands = commands
+	commands["gotolabel"] = func(r RunSet) {
+		if r.Bad("integer", 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		pos, ok := labelTarget(r, r.Stack("integer").Pop().(int64))
+		if !ok {
+			r.Noop(InvalidJump)
+			return
+		}
+		jumpTo(r, pos)
+	}
+
+	commands["gotolabelif"] = func(r RunSet) {
+		if r.Bad("boolean", 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+		if r.Stack("boolean").Pop().(bool) {
+			commands["gotolabel"](r)
+		}
+	}
+
+	commands["jump"] = func(r RunSet) {
+		if r.Bad("integer", 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		offset := r.Stack("integer").Pop().(int64)
+		jumpTo(r, r.Cursor().Position+1+offset)
+	}
+
+	commands["jumpif"] = func(r RunSet) {
+		if r.Bad("boolean", 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+		if r.Stack("boolean").Pop().(bool) {
+			commands["jump"](r)
+		}
+	}
+
+	commands["loop"] = func(r RunSet) {
+		if r.CurrentIndex() < 0 {
+			r.Noop(LoopInBlock)
+			return
+		}
+		if r.Bad("integer", 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		times := r.Stack("integer").Pop().(int64)
+		if times < 1 {
+			jumpTo(r, loopEnd(r))
+			return
+		}
+		r.Loops().Push(Loop{r.Cursor().Position, times, 0})
+	}
+
+	commands["next"] = func(r RunSet) {
+		if r.CurrentIndex() < 0 {
+			r.Noop(LoopInBlock)
+			return
+		}
+		if r.Loops().IsEmpty() {
+			r.Noop(UnmatchedNext)
+			return
+		}
+
+		loop := r.Loops().Pop().(Loop)
+		loop.Remaining--
+		if loop.Remaining > 0 {
+			loop.Index++
+			r.Loops().Push(loop)
+			r.Cursor().Position = loop.Start
+		}
+	}
+
+	commands["loopindex"] = func(r RunSet) {
+		if r.Loops().IsEmpty() {
+			r.Noop(StackUnderflow)
+			return
+		}
+		r.Stack("integer").Push(r.Loops().Peek().(Loop).Index)
+	}
+
+	commands["call"] = func(r RunSet) {
+		if r.Bad("integer", 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		pos, ok := labelTarget(r, r.Stack("integer").Pop().(int64))
+		if !ok || pos < 0 || pos > instructionCount(r) {
+			r.Noop(InvalidJump)
+			return
+		}
+
+		if r.MaxCallDepth() > 0 && r.Returns().Size() >= r.MaxCallDepth() {
+			r.Noop(CallDepthExceeded)
+			return
+		}
+
+		r.Returns().Push(r.Cursor().Position)
+		jumpTo(r, pos)
+	}
+
+	commands["return"] = func(r RunSet) {
+		if r.Returns().IsEmpty() {
+			r.Noop(UnmatchedReturn)
+			return
+		}
+
+		r.Cursor().Position = r.Returns().Pop().(int64)
+	}
+
+	return commands
 }
@@ -128,6 +128,13 @@ func (s *stack) index(idx int64) int64 {
 	return s.Size() - idx - 1
 }
 
+// wrapIndex converts n to an index within a list of length. Indices wrap
+// around so negative numbers count back from the end.
+func wrapIndex(n int64, length int) int {
+	l := int64(length)
+	return int((n%l + l) % l)
+}
+
 // Yank pulls an item of the specified index off the stack and places it on top.
 func (s *stack) Yank(idx int64) {
 	i := s.index(idx)
@@ -137,9 +144,9 @@ func (s *stack) Yank(idx int64) {
 	}
 
 	e := s.elements[i]
-	s.elements = append(
-		append(s.elements[:i], s.elements[i+1:]...), e,
-	)
+	elements := append(Elements{}, s.elements[:i]...)
+	elements = append(elements, s.elements[i+1:]...)
+	s.elements = append(elements, e)
 }
 
 // YankDup copies an item of the specified index and places the copy on top of the stack.
@@ -153,12 +160,20 @@ func (s *stack) YankDup(idx int64) {
 	s.elements = append(s.elements, e)
 }
 
-// Shove inserts the item at the specified index.
+// Shove inserts the item so that it ends up at the specified index.
 func (s *stack) Shove(e Element, idx int64) {
 	i := s.index(idx)
 	if i > s.Size()-1 || i < 0 {
 		return
 	}
 
-	s.elements = append(append(s.elements[:i], e), s.elements[i:]...)
+	// The new element goes above the element currently at the index
+	elements := append(Elements{}, s.elements[:i+1]...)
+	elements = append(elements, e)
+	s.elements = append(elements, s.elements[i+1:]...)
+}
+
+// clone returns a copy of the stack that does not share its elements.
+func (s *stack) clone() stack {
+	return stack{append(Elements{}, s.elements...)}
 }
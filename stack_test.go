@@ -165,6 +165,24 @@ func TestStack(t *testing.T) {
 				So(s.Pop(), ShouldEqual, "c")
 				So(s.Pop(), ShouldEqual, "x")
 			})
+
+			Convey("The other elements should be kept", func() {
+				So(s.Elements(), ShouldResemble, Elements{"a", "b", "x", "c", "d"})
+			})
+		})
+
+		Convey("When the stack is cloned", func() {
+			c := s.clone()
+			c.Shove("x", 2)
+			c.Yank(3)
+			c.Swap()
+			s.Pop()
+			s.Push("y")
+
+			Convey("Changes don't affect each other", func() {
+				So(s.Elements(), ShouldResemble, Elements{"a", "b", "c", "y"})
+				So(c.Elements(), ShouldResemble, Elements{"a", "x", "c", "b", "d"})
+			})
 		})
 
 		Convey("Has() with number of elements within Size() should return true", func() {
@@ -0,0 +1,178 @@
+package spogoto
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// NewStringStack generates a string DataStack.
+func NewStringStack(strs []string) *datastack {
+	elements := Elements{}
+	for _, v := range strs {
+		elements = append(elements, v)
+	}
+	d := NewDataStack(elements, FunctionMap{}, func(str string) (Element, bool) {
+		val, err := strconv.Unquote(str)
+		return Element(val), err == nil
+	})
+	addStringFunctions(d)
+	return d
+}
+
+// StringStackConstructor creates an empty string stack.
+func StringStackConstructor() (string, DataStack) {
+	return "string", NewStringStack([]string{})
+}
+
+// clampIndex limits n to the range from 0 to length.
+func clampIndex(n int64, length int) int {
+	if n < 0 {
+		return 0
+	}
+	if n > int64(length) {
+		return length
+	}
+	return int(n)
+}
+
+// stringFits checks if a string of length characters is within
+// Options.MaxStringLength. Otherwise it records a noop.
+func stringFits(length int, r RunSet, i Interpreter) bool {
+	if !withinLimit(int64(length), i.Config().MaxStringLength, defaultMaxStringLength) {
+		r.Noop(SizeLimitExceeded)
+		return false
+	}
+	return true
+}
+
+// pushFrom pops an element of the stack of type t and pushes it to d
+// formatted as a string if it is within Options.MaxStringLength.
+func pushFrom(d DataStack, r RunSet, i Interpreter, t string, format func(Element) string) {
+	if r.Bad(t, 1) {
+		r.Noop(StackUnderflow)
+		return
+	}
+
+	s := format(r.Stack(t).Peek())
+	if !stringFits(utf8.RuneCountInString(s), r, i) {
+		return
+	}
+	r.Stack(t).Pop()
+	d.Push(s)
+}
+
+func addStringFunctions(ds *datastack) {
+
+	ds.FunctionMap["concat"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(2) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		s1 := d.Pop().(string)
+		s2 := d.Peek().(string)
+		if !stringFits(utf8.RuneCountInString(s1)+utf8.RuneCountInString(s2), r, i) {
+			d.Push(s1)
+			return
+		}
+
+		d.Pop()
+		d.Push(s2 + s1)
+	}
+
+	ds.FunctionMap["length"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		r.Stack("integer").Push(int64(len([]rune(d.Pop().(string)))))
+	}
+
+	ds.FunctionMap["take"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) || r.Bad("integer", 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		runes := []rune(d.Pop().(string))
+		n := clampIndex(r.Stack("integer").Pop().(int64), len(runes))
+		d.Push(string(runes[:n]))
+	}
+
+	ds.FunctionMap["drop"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) || r.Bad("integer", 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		runes := []rune(d.Pop().(string))
+		n := clampIndex(r.Stack("integer").Pop().(int64), len(runes))
+		d.Push(string(runes[n:]))
+	}
+
+	ds.FunctionMap["reverse"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		runes := []rune(d.Pop().(string))
+		for a, b := 0, len(runes)-1; a < b; a, b = a+1, b-1 {
+			runes[a], runes[b] = runes[b], runes[a]
+		}
+		d.Push(string(runes))
+	}
+
+	ds.FunctionMap["contains"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(2) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		s1 := d.Pop().(string)
+		s2 := d.Pop().(string)
+		r.Stack("boolean").Push(strings.Contains(s2, s1))
+	}
+
+	ds.FunctionMap["split"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		fields := strings.Fields(d.Pop().(string))
+		for k := len(fields) - 1; k >= 0; k-- {
+			d.Push(fields[k])
+		}
+	}
+
+	ds.FunctionMap["="] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(2) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		r.Stack("boolean").Push(d.Pop().(string) == d.Pop().(string))
+	}
+
+	ds.FunctionMap["frominteger"] = func(d DataStack, r RunSet, i Interpreter) {
+		pushFrom(d, r, i, "integer", func(e Element) string {
+			return strconv.FormatInt(e.(int64), 10)
+		})
+	}
+
+	ds.FunctionMap["fromfloat"] = func(d DataStack, r RunSet, i Interpreter) {
+		pushFrom(d, r, i, "float", func(e Element) string {
+			return strconv.FormatFloat(e.(float64), 'f', -1, 64)
+		})
+	}
+
+	ds.FunctionMap["fromboolean"] = func(d DataStack, r RunSet, i Interpreter) {
+		pushFrom(d, r, i, "boolean", func(e Element) string {
+			return strconv.FormatBool(e.(bool))
+		})
+	}
+
+}
@@ -0,0 +1,106 @@
+package spogoto
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func stringElements(strs []string) Elements {
+	elements := Elements{}
+	for _, v := range strs {
+		elements = append(elements, v)
+	}
+	return elements
+}
+
+func TestStringStackFunctions(t *testing.T) {
+	testData := []struct {
+		code         string
+		stringsAfter []string
+		intsAfter    []int64
+		boolsAfter   []bool
+	}{
+		{`"foo" "bar" string.concat`, []string{"foobar"}, []int64{}, []bool{}},
+		{`"héllo" string.length`, []string{}, []int64{5}, []bool{}},
+		{`"hello" 2 string.take`, []string{"he"}, []int64{}, []bool{}},
+		{`"hello" 9 string.take`, []string{"hello"}, []int64{}, []bool{}},
+		{`"hello" 2 string.drop`, []string{"llo"}, []int64{}, []bool{}},
+		{`"hello" -2 string.drop`, []string{"hello"}, []int64{}, []bool{}},
+		{`"hello" string.reverse`, []string{"olleh"}, []int64{}, []bool{}},
+		{`"hello" "ell" string.contains`, []string{}, []int64{}, []bool{true}},
+		{`"hello" "elo" string.contains`, []string{}, []int64{}, []bool{false}},
+		{`"a b  c" string.split`, []string{"c", "b", "a"}, []int64{}, []bool{}},
+		{`"a" "a" string.=`, []string{}, []int64{}, []bool{true}},
+		{`"a" "b" string.=`, []string{}, []int64{}, []bool{false}},
+		{`-12 string.frominteger`, []string{"-12"}, []int64{}, []bool{}},
+		{`1.5 string.fromfloat`, []string{"1.5"}, []int64{}, []bool{}},
+		{`true string.fromboolean`, []string{"true"}, []int64{}, []bool{}},
+		{`"a\"b" string.length`, []string{}, []int64{3}, []bool{}},
+		{`string.concat string.take string.split`, []string{}, []int64{}, []bool{}},
+	}
+
+	for _, d := range testData {
+		Convey(fmt.Sprintf("Running code `%s`", d.code), t, func() {
+			i := NewInterpreter(DefaultOptions)
+			r := i.Run(CodeFromString(d.code), StackState{})
+
+			Convey(fmt.Sprintf("String stack should be %v", d.stringsAfter), func() {
+				So(r.Stack("string").Elements(), ShouldResemble, stringElements(d.stringsAfter))
+				So(r.Stack("integer").Elements(), ShouldResemble, int64Elements(d.intsAfter))
+				So(r.Stack("boolean").Elements(), ShouldResemble, boolElements(d.boolsAfter))
+			})
+		})
+	}
+}
+
+func TestStringLengthLimit(t *testing.T) {
+	Convey("Given a string that keeps growing", t, func() {
+		i := NewInterpreter(DefaultOptions)
+		r := i.Run(CodeFromString(`"ab" exec.y (string.dup string.concat)`), StackState{})
+
+		Convey("concat stops at the max string length", func() {
+			So(len(r.Stack("string").Peek().(string)), ShouldBeLessThanOrEqualTo, defaultMaxStringLength)
+			So(r.Errors(), ShouldContain, RunError{-1, "string.concat", SizeLimitExceeded})
+		})
+	})
+
+	Convey("Given a max string length option", t, func() {
+		options := DefaultOptions
+		options.MaxStringLength = 3
+
+		Convey("concat leaves the strings unchanged", func() {
+			r := NewInterpreter(options).Run(CodeFromString(`"ab" "cd" string.concat`), StackState{})
+			So(r.Stack("string").Elements(), ShouldResemble, stringElements([]string{"ab", "cd"}))
+			So(r.Errors(), ShouldResemble, []RunError{{2, "string.concat", SizeLimitExceeded}})
+		})
+
+		Convey("frominteger leaves the integer unchanged", func() {
+			r := NewInterpreter(options).Run(CodeFromString(`1234 string.frominteger`), StackState{})
+			So(r.Stack("string").Elements(), ShouldResemble, Elements{})
+			So(r.Stack("integer").Elements(), ShouldResemble, int64Elements([]int64{1234}))
+		})
+
+		Convey("a negative max means there is no limit", func() {
+			options.MaxStringLength = -1
+			r := NewInterpreter(options).Run(CodeFromString(`"ab" "cd" string.concat`), StackState{})
+			So(r.Stack("string").Elements(), ShouldResemble, stringElements([]string{"abcd"}))
+		})
+	})
+}
+
+func TestStringStackConstructor(t *testing.T) {
+	Convey("Given a string stack constructor", t, func() {
+		stackType, stack := StringStackConstructor()
+
+		Convey("It should return string stack type", func() {
+			So(stackType, ShouldEqual, "string")
+		})
+
+		Convey("It should push unquoted string literals", func() {
+			stack.PushLiteral(`"a\tb"`)
+			stack.PushLiteral(`"bad`)
+			So(stack.Elements(), ShouldResemble, Elements{"a\tb"})
+		})
+	})
+}
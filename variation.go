@@ -0,0 +1,204 @@
+package spogoto
+
+import (
+	"math"
+)
+
+// UniformMutation returns a copy of code where each item is replaced with
+// a random Instruction with a probability of rate.
+func UniformMutation(i Interpreter, code Code, rate float64, r Rand) Code {
+	gen := i.WithRand(r)
+	child := Code{}
+	for _, item := range code {
+		if r.Float64() < rate {
+			item = gen.RandomInstruction()
+		}
+		child = append(child, item)
+	}
+	return child
+}
+
+// UniformInsertion returns a copy of code where a random Instruction is
+// inserted after each item with a probability of rate.
+func UniformInsertion(i Interpreter, code Code, rate float64, r Rand) Code {
+	gen := i.WithRand(r)
+	child := Code{}
+	for _, item := range code {
+		child = append(child, item)
+		if r.Float64() < rate {
+			child = append(child, gen.RandomInstruction())
+		}
+	}
+	return child
+}
+
+// UniformDeletion returns a copy of code where each item is removed with a
+// probability of rate.
+func UniformDeletion(code Code, rate float64, r Rand) Code {
+	child := Code{}
+	for _, item := range code {
+		if r.Float64() >= rate {
+			child = append(child, item)
+		}
+	}
+	return child
+}
+
+// UniformCloseMutation returns a copy of code where the block closing after
+// each item is changed with a probability of rate. A ")" following the item
+// is removed or a new ")" is added after it with equal chance.
+func UniformCloseMutation(code Code, rate float64, r Rand) Code {
+	child := Code{}
+	for k := 0; k < len(code); k++ {
+		child = append(child, code[k])
+		if code[k] == ")" || r.Float64() >= rate {
+			continue
+		}
+		if r.Float64() < 0.5 {
+			child = append(child, ")")
+		} else if k+1 < len(code) && code[k+1] == ")" {
+			k++
+		}
+	}
+	return child
+}
+
+// UniformCrossover creates a child by taking the item at each position
+// from b with a probability of rate and from a otherwise. Positions that the
+// chosen parent doesn't have are skipped.
+func UniformCrossover(a, b Code, rate float64, r Rand) Code {
+	length := len(a)
+	if len(b) > length {
+		length = len(b)
+	}
+	child := Code{}
+	for k := 0; k < length; k++ {
+		parent := a
+		if r.Float64() < rate {
+			parent = b
+		}
+		if k < len(parent) {
+			child = append(child, parent[k])
+		}
+	}
+	return child
+}
+
+// OnePointCrossover joins the start of a to the end of b with a
+// probability of rate and copies a otherwise. Each parent is cut at a
+// random point.
+func OnePointCrossover(a, b Code, rate float64, r Rand) Code {
+	if !crosses(rate, r) {
+		return append(Code{}, a...)
+	}
+	child := append(Code{}, a[:randomCut(a, r)]...)
+	return append(child, b[randomCut(b, r):]...)
+}
+
+// TwoPointCrossover replaces a random segment of a with a random segment of
+// b with a probability of rate and copies a otherwise.
+func TwoPointCrossover(a, b Code, rate float64, r Rand) Code {
+	if !crosses(rate, r) {
+		return append(Code{}, a...)
+	}
+	aStart, aEnd := randomSegment(a, r)
+	bStart, bEnd := randomSegment(b, r)
+	child := append(Code{}, a[:aStart]...)
+	child = append(child, b[bStart:bEnd]...)
+	return append(child, a[aEnd:]...)
+}
+
+// SizeFairCrossover replaces a random segment of the parsed Instructions
+// of a with a random segment of the parsed Instructions of b that is no
+// larger than the replaced one with a probability of rate and copies a
+// otherwise. Segments never cut through blocks and their sizes count the
+// Instructions inside blocks. A segment of size one can replace an empty
+// segment so that children can also grow.
+func SizeFairCrossover(i Interpreter, a, b Code, rate float64, r Rand) Code {
+	if !crosses(rate, r) {
+		return append(Code{}, a...)
+	}
+	pa, pb := i.Parse(a), i.Parse(b)
+	aStart, aEnd := randomItems(int64(len(pa)), r)
+	limit := pa[aStart:aEnd].Size()
+	if limit < 1 {
+		limit = 1
+	}
+
+	// sizes[k] is the size of the first k Instructions of b
+	sizes := make([]int64, len(pb)+1)
+	for k, in := range pb {
+		sizes[k+1] = sizes[k] + InstructionSet{in}.Size()
+	}
+	type segment struct{ start, end int }
+	fair := []segment{}
+	for start := range pb {
+		for end := start + 1; end <= len(pb) && sizes[end]-sizes[start] <= limit; end++ {
+			fair = append(fair, segment{start, end})
+		}
+	}
+
+	child := append(InstructionSet{}, pa[:aStart]...)
+	if len(fair) > 0 {
+		donated := fair[r.Int63n(int64(len(fair)))]
+		child = append(child, pb[donated.start:donated.end]...)
+	}
+	child = append(child, pa[aEnd:]...)
+	return child.Code()
+}
+
+// Alternation creates a child by copying items from one parent while
+// switching to the other parent with a probability of rate after each
+// item (ULTRA). On a switch the position in the other parent is moved by a
+// normally distributed amount with a standard deviation of deviation. The
+// child is at most as long as both parents together.
+func Alternation(a, b Code, rate float64, deviation float64, r Rand) Code {
+	parents := []Code{a, b}
+	current := r.Int63n(2)
+	child := Code{}
+	var idx int64
+	for idx < int64(len(parents[current])) && len(child) < len(a)+len(b) {
+		child = append(child, parents[current][idx])
+		idx++
+		if r.Float64() < rate {
+			current = 1 - current
+			idx += int64(math.Floor(gaussian(r)*deviation + 0.5))
+			if idx < 0 {
+				idx = 0
+			}
+		}
+	}
+	return child
+}
+
+// crosses returns true with a probability of rate. Nothing is drawn from r
+// when rate is 1 or more.
+func crosses(rate float64, r Rand) bool {
+	return rate >= 1 || r.Float64() < rate
+}
+
+// randomCut returns a random index from 0 to the length of code.
+func randomCut(code Code, r Rand) int64 {
+	return r.Int63n(int64(len(code)) + 1)
+}
+
+// randomItems returns the start and end of a random segment of n items.
+func randomItems(n int64, r Rand) (int64, int64) {
+	start, end := r.Int63n(n+1), r.Int63n(n+1)
+	if start > end {
+		start, end = end, start
+	}
+	return start, end
+}
+
+// randomSegment returns the start and end of a random segment of code.
+func randomSegment(code Code, r Rand) (int64, int64) {
+	return randomItems(int64(len(code)), r)
+}
+
+// gaussian returns a normally distributed number with a mean of 0 and a
+// standard deviation of 1.
+func gaussian(r Rand) float64 {
+	u := 1 - r.Float64()
+	return math.Sqrt(-2*math.Log(u)) * math.Cos(2*math.Pi*r.Float64())
+}
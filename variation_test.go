@@ -0,0 +1,168 @@
+package spogoto
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"math/rand"
+	"testing"
+)
+
+func TestVariationOperators(t *testing.T) {
+	i := NewInterpreter(DefaultOptions)
+	a := CodeFromString("1 2 3 4 5")
+	b := CodeFromString("6 7 8")
+
+	Convey("Given a seeded Rand", t, func() {
+		newRand := func() Rand { return rand.New(rand.NewSource(7)) }
+
+		Convey("Operators are deterministic under the Rand", func() {
+			So(UniformMutation(i, a, 0.5, newRand()), ShouldResemble, UniformMutation(i, a, 0.5, newRand()))
+			So(UniformInsertion(i, a, 0.5, newRand()), ShouldResemble, UniformInsertion(i, a, 0.5, newRand()))
+			So(Alternation(a, b, 0.5, 1, newRand()), ShouldResemble, Alternation(a, b, 0.5, 1, newRand()))
+		})
+
+		Convey("Operators don't change the parents", func() {
+			r := newRand()
+			UniformMutation(i, a, 1, r)
+			UniformDeletion(a, 1, r)
+			UniformCloseMutation(a, 1, r)
+			TwoPointCrossover(a, b, 1, r)
+			Alternation(a, b, 1, 2, r)
+			So(a, ShouldResemble, CodeFromString("1 2 3 4 5"))
+			So(b, ShouldResemble, CodeFromString("6 7 8"))
+		})
+
+		Convey("Uniform mutation replaces items with the rate", func() {
+			So(UniformMutation(i, a, 0, newRand()), ShouldResemble, a)
+			child := UniformMutation(i, a, 1, newRand())
+			So(child, ShouldHaveLength, 5)
+			So(child, ShouldNotResemble, a)
+		})
+
+		Convey("Uniform insertion adds items with the rate", func() {
+			So(UniformInsertion(i, a, 0, newRand()), ShouldResemble, a)
+			child := UniformInsertion(i, a, 1, newRand())
+			So(child, ShouldHaveLength, 10)
+			So(child[0], ShouldEqual, "1")
+			So(child[2], ShouldEqual, "2")
+		})
+
+		Convey("Uniform deletion removes items with the rate", func() {
+			So(UniformDeletion(a, 0, newRand()), ShouldResemble, a)
+			So(UniformDeletion(a, 1, newRand()), ShouldResemble, Code{})
+		})
+
+		Convey("Uniform close mutation only changes closing parentheses", func() {
+			code := CodeFromString("( 1 ) 2")
+			So(UniformCloseMutation(code, 0, newRand()), ShouldResemble, code)
+			for k := 0; k < 10; k++ {
+				child := UniformCloseMutation(code, 1, newRand())
+				items := Code{}
+				for _, item := range child {
+					if item != ")" {
+						items = append(items, item)
+					}
+				}
+				So(items, ShouldResemble, CodeFromString("( 1 2"))
+			}
+		})
+
+		Convey("Uniform crossover takes items from b with the rate", func() {
+			So(UniformCrossover(a, b, 0, newRand()), ShouldResemble, a)
+			So(UniformCrossover(a, b, 1, newRand()), ShouldResemble, b)
+			r := newRand()
+			for k := 0; k < 20; k++ {
+				child := UniformCrossover(a, b, 0.5, r)
+				So(len(child), ShouldBeBetweenOrEqual, len(b), len(a))
+				for n := range b {
+					So(child[n], ShouldBeIn, []string{a[n], b[n]})
+				}
+			}
+		})
+
+		Convey("Crossovers copy a when they don't cross over", func() {
+			So(OnePointCrossover(a, b, 0, newRand()), ShouldResemble, a)
+			So(TwoPointCrossover(a, b, 0, newRand()), ShouldResemble, a)
+			So(SizeFairCrossover(i, a, b, 0, newRand()), ShouldResemble, a)
+		})
+
+		Convey("One point crossover joins a start of a and an end of b", func() {
+			r := newRand()
+			for k := 0; k < 20; k++ {
+				child := OnePointCrossover(a, b, 1, r)
+				n := 0
+				for n < len(child) && n < len(a) && child[n] == a[n] {
+					n++
+				}
+				So(b[len(b)-(len(child)-n):], ShouldResemble, child[n:])
+			}
+		})
+
+		Convey("Two point crossover replaces a segment of a", func() {
+			r := newRand()
+			for k := 0; k < 20; k++ {
+				child := TwoPointCrossover(a, b, 1, r)
+				So(len(child), ShouldBeBetweenOrEqual, 0, len(a)+len(b))
+			}
+
+			child := TwoPointCrossover(a, Code{}, 1, r)
+			n := 0
+			for n < len(child) && child[n] == a[n] {
+				n++
+			}
+			So(a[len(a)-(len(child)-n):], ShouldResemble, child[n:])
+		})
+
+		Convey("Size fair crossover limits the size of the inserted segment", func() {
+			r := newRand()
+			long := CodeFromString("10 11 12 13 14 15 16 17 18 19 20 21 22 23 24 25")
+			for k := 0; k < 50; k++ {
+				So(i.Parse(SizeFairCrossover(i, a, long, 1, r)).Size(), ShouldBeLessThanOrEqualTo, len(a)+1)
+				So(i.Parse(SizeFairCrossover(i, Code{}, long, 1, r)).Size(), ShouldBeLessThanOrEqualTo, 1)
+			}
+			So(len(SizeFairCrossover(i, a, Code{}, 1, r)), ShouldBeLessThanOrEqualTo, len(a))
+		})
+
+		Convey("Size fair crossover measures parsed Instructions", func() {
+			r := newRand()
+			blocks := CodeFromString("( 1 2 ) foo.bar ( 3 ( 4 ) )")
+			for k := 0; k < 50; k++ {
+				child := SizeFairCrossover(i, blocks, blocks, 1, r)
+				So(child, ShouldNotContain, "foo.bar")
+				opened := 0
+				for _, item := range child {
+					if item == "(" {
+						opened++
+					} else if item == ")" {
+						opened--
+					}
+					So(opened, ShouldBeGreaterThanOrEqualTo, 0)
+				}
+				So(opened, ShouldEqual, 0)
+			}
+		})
+
+		Convey("Alternation without switching copies a parent", func() {
+			So(Alternation(a, b, 0, 0, newRand()), ShouldBeIn, []Code{a, b})
+		})
+
+		Convey("Alternation is at most as long as both parents", func() {
+			r := newRand()
+			for k := 0; k < 20; k++ {
+				So(len(Alternation(a, b, 0.8, 3, r)), ShouldBeLessThanOrEqualTo, 8)
+			}
+		})
+	})
+
+	Convey("Given an Interpreter with a different Rand", t, func() {
+		r := rand.New(rand.NewSource(7))
+		c := i.WithRand(r)
+
+		Convey("It shares the Parser of the Interpreter", func() {
+			So(c.Parse(CodeFromString("integer.+")), ShouldResemble, i.Parse(CodeFromString("integer.+")))
+		})
+
+		Convey("It generates random values with the Rand", func() {
+			So(c.RandFloat(), ShouldEqual, rand.New(rand.NewSource(7)).Float64())
+		})
+	})
+}
@@ -0,0 +1,356 @@
+package spogoto
+
+import (
+	"strconv"
+	"strings"
+)
+
+// vectorScalars maps vector DataStack types to the type of their items.
+var vectorScalars = map[string]string{
+	"vector_integer": "integer",
+	"vector_float":   "float",
+	"vector_boolean": "boolean",
+}
+
+// NewVectorIntegerStack generates a vector_integer DataStack.
+func NewVectorIntegerStack(vectors [][]int64) *datastack {
+	elements := Elements{}
+	for _, v := range vectors {
+		elements = append(elements, v)
+	}
+	return newVectorStack("vector_integer", elements)
+}
+
+// NewVectorFloatStack generates a vector_float DataStack.
+func NewVectorFloatStack(vectors [][]float64) *datastack {
+	elements := Elements{}
+	for _, v := range vectors {
+		elements = append(elements, v)
+	}
+	return newVectorStack("vector_float", elements)
+}
+
+// NewVectorBooleanStack generates a vector_boolean DataStack.
+func NewVectorBooleanStack(vectors [][]bool) *datastack {
+	elements := Elements{}
+	for _, v := range vectors {
+		elements = append(elements, v)
+	}
+	return newVectorStack("vector_boolean", elements)
+}
+
+// VectorIntegerStackConstructor creates an empty vector_integer stack.
+func VectorIntegerStackConstructor() (string, DataStack) {
+	return "vector_integer", NewVectorIntegerStack([][]int64{})
+}
+
+// VectorFloatStackConstructor creates an empty vector_float stack.
+func VectorFloatStackConstructor() (string, DataStack) {
+	return "vector_float", NewVectorFloatStack([][]float64{})
+}
+
+// VectorBooleanStackConstructor creates an empty vector_boolean stack.
+func VectorBooleanStackConstructor() (string, DataStack) {
+	return "vector_boolean", NewVectorBooleanStack([][]bool{})
+}
+
+func newVectorStack(t string, elements Elements) *datastack {
+	scalar := vectorScalars[t]
+	d := NewDataStack(elements, FunctionMap{}, func(str string) (Element, bool) {
+		return parseVector(str, scalar)
+	})
+	addVectorFunctions(d, t, scalar)
+	return d
+}
+
+// parseVector converts a bracketed vector literal with items of type
+// scalar to a vector.
+func parseVector(str string, scalar string) (Element, bool) {
+	if !strings.HasPrefix(str, "[") || !strings.HasSuffix(str, "]") {
+		return nil, false
+	}
+
+	items := Elements{}
+	for _, field := range strings.Fields(str[1 : len(str)-1]) {
+		var item Element
+		var err error
+		switch scalar {
+		case "integer":
+			item, err = strconv.ParseInt(field, 10, 64)
+		case "float":
+			item, err = strconv.ParseFloat(field, 64)
+		case "boolean":
+			if field != "true" && field != "false" {
+				return nil, false
+			}
+			item = field == "true"
+		}
+		if err != nil {
+			return nil, false
+		}
+		items = append(items, item)
+	}
+
+	return newVector(scalar, items), true
+}
+
+// formatVector formats a vector as a bracketed vector literal.
+func formatVector(vector Element) string {
+	fields := []string{}
+	for _, item := range vectorItems(vector) {
+		switch v := item.(type) {
+		case int64:
+			fields = append(fields, strconv.FormatInt(v, 10))
+		case float64:
+			str, ok := formatFloat(v)
+			if !ok {
+				str = strconv.FormatFloat(v, 'f', -1, 64)
+			}
+			fields = append(fields, str)
+		case bool:
+			fields = append(fields, strconv.FormatBool(v))
+		}
+	}
+	return "[" + strings.Join(fields, " ") + "]"
+}
+
+// vectorItems returns the items of a vector as Elements.
+func vectorItems(vector Element) Elements {
+	items := Elements{}
+	switch v := vector.(type) {
+	case []int64:
+		for _, item := range v {
+			items = append(items, item)
+		}
+	case []float64:
+		for _, item := range v {
+			items = append(items, item)
+		}
+	case []bool:
+		for _, item := range v {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// newVector creates a new vector of items of type scalar.
+func newVector(scalar string, items Elements) Element {
+	switch scalar {
+	case "integer":
+		v := make([]int64, len(items))
+		for k, item := range items {
+			v[k] = item.(int64)
+		}
+		return v
+	case "float":
+		v := make([]float64, len(items))
+		for k, item := range items {
+			v[k] = item.(float64)
+		}
+		return v
+	}
+	v := make([]bool, len(items))
+	for k, item := range items {
+		v[k] = item.(bool)
+	}
+	return v
+}
+
+// vectorFits checks if a vector of length items is within
+// Options.MaxVectorLength. Otherwise it records a noop.
+func vectorFits(length int, r RunSet, i Interpreter) bool {
+	if !withinLimit(int64(length), i.Config().MaxVectorLength, defaultMaxVectorLength) {
+		r.Noop(SizeLimitExceeded)
+		return false
+	}
+	return true
+}
+
+func addVectorFunctions(ds *datastack, t string, scalar string) {
+
+	// lacks returns true if there is no vector or if there are less than the
+	// number of integers and items needed on their stacks.
+	lacks := func(d DataStack, r RunSet, integers int64, items int64) bool {
+		needed := map[string]int64{"integer": integers}
+		needed[scalar] += items
+		for stackType, count := range needed {
+			if r.Bad(stackType, count) {
+				return true
+			}
+		}
+		return d.Lack(1)
+	}
+
+	ds.FunctionMap["nth"] = func(d DataStack, r RunSet, i Interpreter) {
+		if lacks(d, r, 1, 0) || len(vectorItems(d.Peek())) == 0 {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		n := r.Stack("integer").Pop().(int64)
+		items := vectorItems(d.Pop())
+		r.Stack(scalar).Push(items[wrapIndex(n, len(items))])
+	}
+
+	ds.FunctionMap["first"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) || len(vectorItems(d.Peek())) == 0 {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		r.Stack(scalar).Push(vectorItems(d.Pop())[0])
+	}
+
+	ds.FunctionMap["last"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) || len(vectorItems(d.Peek())) == 0 {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		items := vectorItems(d.Pop())
+		r.Stack(scalar).Push(items[len(items)-1])
+	}
+
+	ds.FunctionMap["rest"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		items := vectorItems(d.Pop())
+		if len(items) > 0 {
+			items = items[1:]
+		}
+		d.Push(newVector(scalar, items))
+	}
+
+	ds.FunctionMap["conj"] = func(d DataStack, r RunSet, i Interpreter) {
+		if lacks(d, r, 0, 1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+		if !vectorFits(len(vectorItems(d.Peek()))+1, r, i) {
+			return
+		}
+
+		item := r.Stack(scalar).Pop()
+		d.Push(newVector(scalar, append(vectorItems(d.Pop()), item)))
+	}
+
+	ds.FunctionMap["concat"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(2) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		top := d.Pop()
+		if !vectorFits(len(vectorItems(top))+len(vectorItems(d.Peek())), r, i) {
+			d.Push(top)
+			return
+		}
+
+		v1 := vectorItems(top)
+		v2 := vectorItems(d.Pop())
+		d.Push(newVector(scalar, append(v2, v1...)))
+	}
+
+	ds.FunctionMap["reverse"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		items := vectorItems(d.Pop())
+		for a, b := 0, len(items)-1; a < b; a, b = a+1, b-1 {
+			items[a], items[b] = items[b], items[a]
+		}
+		d.Push(newVector(scalar, items))
+	}
+
+	ds.FunctionMap["length"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		r.Stack("integer").Push(int64(len(vectorItems(d.Pop()))))
+	}
+
+	ds.FunctionMap["set"] = func(d DataStack, r RunSet, i Interpreter) {
+		if lacks(d, r, 1, 1) || len(vectorItems(d.Peek())) == 0 {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		n := r.Stack("integer").Pop().(int64)
+		item := r.Stack(scalar).Pop()
+		items := vectorItems(d.Pop())
+		items[wrapIndex(n, len(items))] = item
+		d.Push(newVector(scalar, items))
+	}
+
+	ds.FunctionMap["empty"] = func(d DataStack, r RunSet, i Interpreter) {
+		d.Push(newVector(scalar, Elements{}))
+	}
+
+	ds.FunctionMap["iterate"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		args, ok := execArgs(r, 1)
+		if !ok {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		items := vectorItems(d.Pop())
+		if len(items) == 0 {
+			return
+		}
+
+		body := args[0]
+		r.Stack(scalar).Push(items[0])
+		if len(items) == 1 {
+			pushExec(r.Stack("exec"), body)
+			return
+		}
+
+		rest := newVector(scalar, items[1:])
+		pushExec(
+			r.Stack("exec"), body,
+			NewInstruction(t, formatVector(rest), ""),
+			NewInstruction(t, t+".iterate", "iterate"),
+			body,
+		)
+	}
+
+	if scalar == "boolean" {
+		return
+	}
+
+	ds.FunctionMap["sum"] = func(d DataStack, r RunSet, i Interpreter) {
+		if d.Lack(1) {
+			r.Noop(StackUnderflow)
+			return
+		}
+
+		switch v := d.Pop().(type) {
+		case []int64:
+			var sum int64
+			for _, item := range v {
+				sum += item
+			}
+			r.Stack(scalar).Push(sum)
+		case []float64:
+			sum := 0.0
+			for _, item := range v {
+				sum += item
+			}
+			r.Stack(scalar).Push(sum)
+		}
+	}
+
+}
@@ -0,0 +1,130 @@
+package spogoto
+
+import (
+	"fmt"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestVectorStackFunctions(t *testing.T) {
+	testData := []struct {
+		code        string
+		stack       string
+		vectorAfter Elements
+		intsAfter   []int64
+		floatsAfter []float64
+	}{
+		{"[1 2 3] 1 vector_integer.nth", "vector_integer", Elements{}, []int64{2}, []float64{}},
+		{"[1 2 3] -4 vector_integer.nth", "vector_integer", Elements{}, []int64{3}, []float64{}},
+		{"[1 2 3] -9223372036854775808 vector_integer.nth", "vector_integer", Elements{}, []int64{2}, []float64{}},
+		{"[] 1 vector_integer.nth", "vector_integer", Elements{[]int64{}}, []int64{1}, []float64{}},
+		{"[1.5 2.5] vector_float.first", "vector_float", Elements{}, []int64{}, []float64{1.5}},
+		{"[1.5 2.5] vector_float.last", "vector_float", Elements{}, []int64{}, []float64{2.5}},
+		{"[1 2 3] vector_integer.rest", "vector_integer", Elements{[]int64{2, 3}}, []int64{}, []float64{}},
+		{"[] vector_integer.rest", "vector_integer", Elements{[]int64{}}, []int64{}, []float64{}},
+		{"[1 2] 3 vector_integer.conj", "vector_integer", Elements{[]int64{1, 2, 3}}, []int64{}, []float64{}},
+		{"[1.0] [2.0 3.0] vector_float.concat", "vector_float", Elements{[]float64{1, 2, 3}}, []int64{}, []float64{}},
+		{"[true false false] vector_boolean.reverse", "vector_boolean", Elements{[]bool{false, false, true}}, []int64{}, []float64{}},
+		{"[true false] vector_boolean.length", "vector_boolean", Elements{}, []int64{2}, []float64{}},
+		{"[1 2 3] 9 1 vector_integer.set", "vector_integer", Elements{[]int64{1, 9, 3}}, []int64{}, []float64{}},
+		{"[1 2 3] 5 -9223372036854775808 vector_integer.set", "vector_integer", Elements{[]int64{1, 5, 3}}, []int64{}, []float64{}},
+		{"[1 2 3] vector_integer.sum", "vector_integer", Elements{}, []int64{6}, []float64{}},
+		{"[0.5 0.25] vector_float.sum", "vector_float", Elements{}, []int64{}, []float64{0.75}},
+		{"vector_float.empty", "vector_float", Elements{[]float64{}}, []int64{}, []float64{}},
+		{"[1 2 3] vector_integer.iterate integer.dup", "vector_integer", Elements{}, []int64{1, 1, 2, 2, 3, 3}, []float64{}},
+		{"[] vector_integer.iterate integer.dup 5", "vector_integer", Elements{}, []int64{5}, []float64{}},
+		{"[1.0 NaN] vector_float.iterate float.pop", "vector_float", Elements{}, []int64{}, []float64{}},
+	}
+
+	for _, d := range testData {
+		Convey(fmt.Sprintf("Running code `%s`", d.code), t, func() {
+			i := NewInterpreter(DefaultOptions)
+			r := i.Run(CodeFromString(d.code), StackState{})
+
+			Convey(fmt.Sprintf("The %s stack should be %v", d.stack, d.vectorAfter), func() {
+				So(r.Stack(d.stack).Elements(), ShouldResemble, d.vectorAfter)
+				So(r.Stack("integer").Elements(), ShouldResemble, int64Elements(d.intsAfter))
+				So(r.Stack("float").Elements(), ShouldResemble, float64Elements(d.floatsAfter))
+			})
+		})
+	}
+
+	Convey("Given vectors that are shared after a dup", t, func() {
+		i := NewInterpreter(DefaultOptions)
+		r := i.Run(CodeFromString("[1 2] vector_integer.dup 9 0 vector_integer.set"), StackState{})
+
+		Convey("Changing one does not change the other", func() {
+			So(r.Stack("vector_integer").Elements(), ShouldResemble, Elements{[]int64{1, 2}, []int64{9, 2}})
+		})
+	})
+}
+
+func TestVectorLengthLimit(t *testing.T) {
+	Convey("Given a vector that keeps growing", t, func() {
+		i := NewInterpreter(DefaultOptions)
+		r := i.Run(CodeFromString("[1] exec.y (vector_integer.dup vector_integer.concat)"), StackState{})
+
+		Convey("concat stops at the max vector length", func() {
+			So(len(r.Stack("vector_integer").Peek().([]int64)), ShouldBeLessThanOrEqualTo, defaultMaxVectorLength)
+			So(r.Errors(), ShouldContain, RunError{-1, "vector_integer.concat", SizeLimitExceeded})
+		})
+	})
+
+	Convey("Given a max vector length option", t, func() {
+		options := DefaultOptions
+		options.MaxVectorLength = 2
+
+		Convey("concat leaves the vectors unchanged", func() {
+			r := NewInterpreter(options).Run(CodeFromString("[1 2] [3] vector_integer.concat"), StackState{})
+			So(r.Stack("vector_integer").Elements(), ShouldResemble, Elements{[]int64{1, 2}, []int64{3}})
+			So(r.Errors(), ShouldResemble, []RunError{{2, "vector_integer.concat", SizeLimitExceeded}})
+		})
+
+		Convey("conj leaves the vector and item unchanged", func() {
+			r := NewInterpreter(options).Run(CodeFromString("[1 2] 3 vector_integer.conj"), StackState{})
+			So(r.Stack("vector_integer").Elements(), ShouldResemble, Elements{[]int64{1, 2}})
+			So(r.Stack("integer").Elements(), ShouldResemble, int64Elements([]int64{3}))
+		})
+
+		Convey("a negative max means there is no limit", func() {
+			options.MaxVectorLength = -1
+			r := NewInterpreter(options).Run(CodeFromString("[1 2] 3 vector_integer.conj"), StackState{})
+			So(r.Stack("vector_integer").Elements(), ShouldResemble, Elements{[]int64{1, 2, 3}})
+		})
+	})
+}
+
+func TestVectorLiterals(t *testing.T) {
+	data := []struct {
+		literal  string
+		kind     string
+		expected Element
+	}{
+		{"[1 -2 3]", "vector_integer", []int64{1, -2, 3}},
+		{"[]", "vector_integer", []int64{}},
+		{"[1.5 2]", "vector_float", []float64{1.5, 2}},
+		{"[true false]", "vector_boolean", []bool{true, false}},
+		{"[1 true]", "", nil},
+		{"[1 2", "", nil},
+	}
+
+	for _, d := range data {
+		Convey(fmt.Sprintf("Given the vector literal %s", d.literal), t, func() {
+			So(vectorLiteralType(d.literal), ShouldEqual, d.kind)
+			if d.kind != "" {
+				v, ok := parseVector(d.literal, vectorScalars[d.kind])
+				So(ok, ShouldBeTrue)
+				So(v, ShouldResemble, d.expected)
+				So(CodeFromString(formatVector(v)), ShouldResemble, Code{formatVector(v)})
+			}
+		})
+	}
+
+	Convey("Given code with vector literals", t, func() {
+		code := CodeFromString("([1 2] [ 3.0 ])")
+
+		Convey("Vector literals are single items", func() {
+			So(code, ShouldResemble, Code{"(", "[1 2]", "[ 3.0 ]", ")"})
+		})
+	})
+}